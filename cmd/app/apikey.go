@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/config"
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository/postgres"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/apikeyservice"
+	postgresdb "github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+)
+
+type apiKeyCommand struct {
+	name      string
+	keyName   string
+	scopes    []string
+	expiresIn time.Duration
+	id        int
+}
+
+func parseAPIKeyCommand(args []string) (apiKeyCommand, error) {
+	if len(args) == 0 {
+		return apiKeyCommand{}, fmt.Errorf("apikey command is required")
+	}
+
+	cmd := apiKeyCommand{name: args[0]}
+	switch cmd.name {
+	case "create":
+		var scopes string
+
+		fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.StringVar(&cmd.keyName, "name", "", "key name")
+		fs.StringVar(&scopes, "scopes", "", "comma separated scopes")
+		fs.DurationVar(&cmd.expiresIn, "expires-in", 0, "key lifetime, 0 means the key never expires")
+		if err := fs.Parse(args[1:]); err != nil {
+			return apiKeyCommand{}, err
+		}
+
+		if cmd.keyName == "" || fs.NArg() > 0 || cmd.expiresIn < 0 {
+			return apiKeyCommand{}, fmt.Errorf("apikey create takes --name and optional --scopes and --expires-in")
+		}
+
+		for _, scope := range strings.Split(scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope == "" {
+				continue
+			}
+			if !slices.Contains(model.Scopes, scope) {
+				return apiKeyCommand{}, fmt.Errorf("unknown scope %q, expected one of %s", scope, strings.Join(model.Scopes, ","))
+			}
+			cmd.scopes = append(cmd.scopes, scope)
+		}
+	case "list":
+		if len(args) != 1 {
+			return apiKeyCommand{}, fmt.Errorf("apikey list takes no arguments")
+		}
+	case "revoke":
+		if len(args) != 2 {
+			return apiKeyCommand{}, fmt.Errorf("apikey revoke takes exactly one key id")
+		}
+
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			return apiKeyCommand{}, fmt.Errorf("invalid api key id %q", args[1])
+		}
+		cmd.id = id
+	default:
+		return apiKeyCommand{}, fmt.Errorf("unknown apikey command %q", cmd.name)
+	}
+
+	return cmd, nil
+}
+
+// apiKey runs apikey subcommand, its result is written to out.
+func apiKey(cfg *config.Config, log *slog.Logger, cmd apiKeyCommand, out io.Writer) error {
+	postgresDB, err := postgresdb.New(cfg.Postgres.DSN)
+	if err != nil {
+		return err
+	}
+	defer postgresDB.Close()
+	log.Debug("Postgres connected", slog.String("dsn", cfg.Postgres.DSN))
+
+	ctx := context.Background()
+	apiKeyService := apikeyservice.New(log, postgres.NewAPIKeyRepository(postgresDB))
+
+	switch cmd.name {
+	case "create":
+		input := apikeyservice.CreateKeyInput{Name: cmd.keyName, Scopes: cmd.scopes}
+		if cmd.expiresIn > 0 {
+			expiresAt := time.Now().Add(cmd.expiresIn)
+			input.ExpiresAt = &expiresAt
+		}
+
+		key, value, err := apiKeyService.CreateKey(ctx, input)
+		if err != nil {
+			return err
+		}
+
+		log.Info("Api key created", slog.Int("id", key.ID), slog.String("name", key.Name))
+		fmt.Fprintln(out, value)
+	case "list":
+		keys, err := apiKeyService.GetKeys(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tEXPIRES_AT\tLAST_USED_AT\tREVOKED_AT")
+		for _, key := range keys {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", key.ID, key.Name, key.Prefix, strings.Join(key.Scopes, ","),
+				formatTime(key.ExpiresAt), formatTime(key.LastUsedAt), formatTime(key.RevokedAt))
+		}
+		return tw.Flush()
+	case "revoke":
+		if err = apiKeyService.RevokeKey(ctx, cmd.id); err != nil {
+			return err
+		}
+
+		log.Info("Api key revoked", slog.Int("id", cmd.id))
+	}
+
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
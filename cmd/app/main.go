@@ -1,23 +1,25 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log/slog"
-	"net/http"
 	"os"
 
 	"github.com/4aykovski/effective_mobile_test_task/internal/config"
-	v1 "github.com/4aykovski/effective_mobile_test_task/internal/net/v1"
-	"github.com/4aykovski/effective_mobile_test_task/internal/repository/postgres"
-	"github.com/4aykovski/effective_mobile_test_task/internal/service/carinfoservice"
-	"github.com/4aykovski/effective_mobile_test_task/internal/service/carservice"
-	"github.com/4aykovski/effective_mobile_test_task/internal/service/ownerservice"
-	"github.com/4aykovski/effective_mobile_test_task/migrations"
-	"github.com/4aykovski/effective_mobile_test_task/pkg/client"
-	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
-	postgresdb "github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
 	"github.com/4aykovski/effective_mobile_test_task/pkg/logger"
 )
 
+const usage = `Usage:
+  app [serve] [--no-migrate]             run the API server, migrations are applied first unless --no-migrate is set
+  app migrate up|down|status|redo        apply all, roll back the last, list or reapply the last migration
+  app migrate to VERSION                 apply or roll back migrations until the database is at VERSION
+  app apikey create --name NAME [--scopes SCOPE,...] [--expires-in DURATION]
+                                         create API key and print it, the key can't be shown again
+  app apikey list                        list API keys
+  app apikey revoke ID                   revoke API key
+`
+
 // @title Effective Mobile Test Task - Cars Catalog
 // @version 1.0
 
@@ -26,56 +28,73 @@ import (
 
 // @schemes http https
 
-func main() {
-	cfg := config.MustLoad()
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key created with "app apikey create".
 
-	log, err := logger.New(cfg.Env)
-	if err != nil {
-		log.Error("Failed to initialize logger", slog.String("error", err.Error()))
-		os.Exit(1)
-	}
-	log.Debug("Logger initialized", slog.String("env", cfg.Env))
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description "Bearer " followed by API key or JWT signed with HS256 or RS256. Scopes of JWT are taken from
+// @description space separated "scope" claim or "scopes" array.
 
-	postgresDB, err := postgresdb.New(cfg.Postgres.DSN)
-	if err != nil {
-		log.Error("Failed to connect to postgres", slog.String("error", err.Error()))
-		os.Exit(1)
+func main() {
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 && (args[0] == "serve" || args[0] == "migrate" || args[0] == "apikey") {
+		command, args = args[0], args[1:]
 	}
-	log.Debug("Postgres connected", slog.String("dsn", cfg.Postgres.DSN))
 
-	if err = migrations.RunMigrations(postgresDB.DB); err != nil {
-		log.Error("Failed to run migrations", slog.String("error", err.Error()))
-		os.Exit(1)
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	noMigrate := false
+	if command == "serve" {
+		fs.BoolVar(&noMigrate, "no-migrate", false, "don't apply migrations on start")
 	}
-	log.Info("Migrations applied")
-
-	carRepo := postgres.NewCarRepository(postgresDB)
-	ownerRepo := postgres.NewOwnerRepository(postgresDB)
-	log.Debug("Repositories initialized")
-
-	httpClient := client.NewHTTPClient(cfg.CarsInfoApi.Host, cfg.CarsInfoApi.BasePath, cfg.CarsInfoApi.Scheme, http.Client{})
-	carInfoClient := carinfo.NewClient(httpClient)
-	log.Debug("CarInfoClient initialized")
-
-	carService := carservice.NewCarService(carRepo)
-	ownerService := ownerservice.New(ownerRepo)
-	carInfoService := carinfoservice.New(carInfoClient)
-	log.Debug("Services initialized")
-
-	mux := v1.NewMux(log, carService, ownerService, carInfoService)
-	log.Debug("Mux initialized")
-
-	httpServer := &http.Server{
-		Addr:    cfg.HTTP.Address,
-		Handler: mux,
+	_ = fs.Parse(args)
+
+	var (
+		migrateCmd migrateCommand
+		apiKeyCmd  apiKeyCommand
+		err        error
+	)
+	switch command {
+	case "migrate":
+		migrateCmd, err = parseMigrateCommand(fs.Args())
+	case "apikey":
+		apiKeyCmd, err = parseAPIKeyCommand(fs.Args())
+	default:
+		if fs.NArg() > 0 {
+			err = fmt.Errorf("serve takes no arguments")
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n\n%s", err, usage)
+		os.Exit(2)
 	}
 
-	log.Info("Server started", slog.String("address", cfg.HTTP.Address))
+	cfg := config.MustLoad()
 
-	if err := httpServer.ListenAndServe(); err != nil {
-		log.Error("Failed to start server", slog.String("error", err.Error()))
+	log, err := logger.New(cfg.Env)
+	if err != nil {
+		log.Error("Failed to initialize logger", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	log.Debug("Logger initialized", slog.String("env", cfg.Env))
 
-	log.Error("server stopped")
+	switch command {
+	case "serve":
+		serve(cfg, log, !noMigrate)
+	case "migrate":
+		if err = migrate(cfg, log, migrateCmd); err != nil {
+			log.Error("Failed to migrate", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	case "apikey":
+		if err = apiKey(cfg, log, apiKeyCmd, os.Stdout); err != nil {
+			log.Error("Failed to manage api keys", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/config"
+	"github.com/4aykovski/effective_mobile_test_task/migrations"
+	postgresdb "github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+)
+
+type migrateCommand struct {
+	name    string
+	version int64
+}
+
+func parseMigrateCommand(args []string) (migrateCommand, error) {
+	if len(args) == 0 {
+		return migrateCommand{}, fmt.Errorf("migrate command is required")
+	}
+
+	cmd := migrateCommand{name: args[0]}
+	switch cmd.name {
+	case "up", "down", "status", "redo":
+		if len(args) != 1 {
+			return migrateCommand{}, fmt.Errorf("migrate %s takes no arguments", cmd.name)
+		}
+	case "to":
+		if len(args) != 2 {
+			return migrateCommand{}, fmt.Errorf("migrate to takes exactly one version")
+		}
+
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return migrateCommand{}, fmt.Errorf("invalid migration version %q", args[1])
+		}
+		cmd.version = version
+	default:
+		return migrateCommand{}, fmt.Errorf("unknown migrate command %q", cmd.name)
+	}
+
+	return cmd, nil
+}
+
+func migrate(cfg *config.Config, log *slog.Logger, cmd migrateCommand) error {
+	postgresDB, err := postgresdb.New(cfg.Postgres.DSN)
+	if err != nil {
+		return err
+	}
+	defer postgresDB.Close()
+	log.Debug("Postgres connected", slog.String("dsn", cfg.Postgres.DSN))
+
+	switch cmd.name {
+	case "up":
+		err = migrations.RunMigrations(postgresDB.DB)
+	case "down":
+		err = migrations.Down(postgresDB.DB)
+	case "redo":
+		err = migrations.Redo(postgresDB.DB)
+	case "status":
+		err = migrations.Status(postgresDB.DB)
+	case "to":
+		err = migrations.MigrateTo(postgresDB.DB, cmd.version)
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Info("Migrations done", slog.String("command", cmd.name))
+	return nil
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/4aykovski/effective_mobile_test_task/docs"
+	"github.com/4aykovski/effective_mobile_test_task/internal/config"
+	v1 "github.com/4aykovski/effective_mobile_test_task/internal/net/v1"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository/postgres"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/apikeyservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/carinfoservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/carservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/outboxservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/ownerservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/searchservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/streamservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/webhookservice"
+	"github.com/4aykovski/effective_mobile_test_task/migrations"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/openapi"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/breaker"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
+	postgresdb "github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/jwt"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/ratelimit"
+	"github.com/swaggo/swag"
+)
+
+const (
+	providerPrimary   = "primary"
+	providerSecondary = "secondary"
+	providerDataset   = "dataset"
+)
+
+// serve runs the API server, pending migrations are applied first if migrate is set.
+func serve(cfg *config.Config, log *slog.Logger, migrate bool) {
+	postgresDB, err := postgresdb.New(cfg.Postgres.DSN)
+	if err != nil {
+		log.Error("Failed to connect to postgres", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	log.Debug("Postgres connected", slog.String("dsn", cfg.Postgres.DSN))
+
+	if migrate {
+		if err = migrations.RunMigrations(postgresDB.DB); err != nil {
+			log.Error("Failed to run migrations", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		log.Info("Migrations applied")
+	}
+
+	carRepo := postgres.NewCarRepository(postgresDB)
+	ownerRepo := postgres.NewOwnerRepository(postgresDB)
+	savedSearchRepo := postgres.NewSavedSearchRepository(postgresDB)
+	webhookRepo := postgres.NewWebhookRepository(postgresDB)
+	outboxRepo := postgres.NewOutboxRepository(postgresDB)
+	apiKeyRepo := postgres.NewAPIKeyRepository(postgresDB)
+	log.Debug("Repositories initialized")
+
+	retryPolicy := client.RetryPolicy{
+		MaxAttempts:       cfg.CarsInfoApi.RetryMaxAttempts,
+		BaseBackoff:       cfg.CarsInfoApi.RetryBaseBackoff,
+		MaxBackoff:        cfg.CarsInfoApi.RetryMaxBackoff,
+		RetryableStatuses: cfg.CarsInfoApi.RetryStatuses,
+	}
+	carInfoHTTPClient := http.Client{}
+	if cfg.CarsInfoApi.TLSCertFile != "" || cfg.CarsInfoApi.TLSKeyFile != "" || cfg.CarsInfoApi.TLSCAFile != "" {
+		tlsConfig, err := client.NewTLSConfig(cfg.CarsInfoApi.TLSCertFile, cfg.CarsInfoApi.TLSKeyFile, cfg.CarsInfoApi.TLSCAFile)
+		if err != nil {
+			log.Error("Failed to configure car info api tls", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		carInfoHTTPClient.Transport = transport
+	}
+
+	var carInfoAuth client.Authenticator
+	switch {
+	case cfg.CarsInfoApi.APIKey != "" && cfg.CarsInfoApi.TokenURL != "":
+		log.Error("Car info api key and token url are mutually exclusive")
+		os.Exit(1)
+	case cfg.CarsInfoApi.APIKey != "":
+		carInfoAuth = client.NewAPIKeyAuth(cfg.CarsInfoApi.APIKeyHeader, cfg.CarsInfoApi.APIKey)
+	case cfg.CarsInfoApi.TokenURL != "":
+		carInfoAuth = client.NewBearerTokenAuth(
+			&carInfoHTTPClient,
+			cfg.CarsInfoApi.TokenURL,
+			cfg.CarsInfoApi.TokenClientID,
+			cfg.CarsInfoApi.TokenClientSecret,
+			cfg.CarsInfoApi.TokenScope,
+		)
+	}
+
+	httpClient := client.NewHTTPClient(cfg.CarsInfoApi.Host, cfg.CarsInfoApi.BasePath, cfg.CarsInfoApi.Scheme, carInfoHTTPClient, retryPolicy, carInfoAuth)
+	var carInfoStore carinfo.Store
+	if cfg.CarsInfoApi.CachePersist {
+		carInfoStore = postgres.NewCarInfoCacheRepository(postgresDB)
+	}
+	breakerConfig := breaker.Config{
+		FailureThreshold: cfg.CarsInfoApi.BreakerFailureThreshold,
+		OpenTimeout:      cfg.CarsInfoApi.BreakerOpenTimeout,
+		HalfOpenRequests: cfg.CarsInfoApi.BreakerHalfOpenRequests,
+	}
+	onBreakerStateChange := func(provider string) func(from, to breaker.State) {
+		return func(from, to breaker.State) {
+			log.Warn("Car info api circuit breaker state changed",
+				slog.String("provider", provider), slog.String("from", from.String()), slog.String("to", to.String()))
+		}
+	}
+	breakerClient := carinfo.NewBreakerClient(carinfo.NewClient(httpClient), breaker.New(breakerConfig, onBreakerStateChange(providerPrimary)))
+	carInfoClient := carinfo.NewCachedClient(
+		log,
+		breakerClient,
+		carInfoStore,
+		cfg.CarsInfoApi.CacheSize,
+		cfg.CarsInfoApi.CacheTTL,
+		cfg.CarsInfoApi.CacheNegativeTTL,
+	)
+	log.Debug("CarInfoClient initialized", slog.Bool("persistent_cache", cfg.CarsInfoApi.CachePersist))
+
+	carInfoProviders := make([]carinfoservice.Provider, 0, len(cfg.CarInfoProviders.Order))
+	for _, name := range cfg.CarInfoProviders.Order {
+		switch name {
+		case providerPrimary:
+			carInfoProviders = append(carInfoProviders, carinfoservice.NewProvider(name, carInfoClient))
+		case providerSecondary:
+			secondaryHTTPClient := client.NewHTTPClient(
+				cfg.CarInfoProviders.SecondaryHost,
+				cfg.CarInfoProviders.SecondaryBasePath,
+				cfg.CarInfoProviders.SecondaryScheme,
+				carInfoHTTPClient,
+				retryPolicy,
+				carInfoAuth,
+			)
+			secondaryClient := carinfo.NewBreakerClient(
+				carinfo.NewClientWithMethod(secondaryHTTPClient, cfg.CarInfoProviders.SecondaryMethod),
+				breaker.New(breakerConfig, onBreakerStateChange(name)),
+			)
+			carInfoProviders = append(carInfoProviders, carinfoservice.NewProvider(name, secondaryClient))
+		case providerDataset:
+			dataset, err := carinfo.LoadDataset(cfg.CarInfoProviders.DatasetPath)
+			if err != nil {
+				log.Error("Failed to load car info dataset", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+			carInfoProviders = append(carInfoProviders, carinfoservice.NewProvider(name, dataset))
+		default:
+			log.Error("Unknown car info provider", slog.String("provider", name))
+			os.Exit(1)
+		}
+	}
+	log.Debug("Car info providers initialized", slog.Any("providers", cfg.CarInfoProviders.Order))
+
+	webhookService := webhookservice.New(log, webhookRepo, cfg.Webhooks)
+	streamService := streamservice.New(cfg.Stream.BufferSize)
+	cachedCarRepo := carservice.NewCachedRepository(carRepo, cfg.Cache.CarsSize, cfg.Cache.ListsSize, cfg.Cache.TTL)
+	carService := carservice.NewCarService(cachedCarRepo)
+	ownerService := ownerservice.New(ownerRepo)
+	carInfoService := carinfoservice.New(carInfoProviders, cfg.CarsInfoApi.Concurrency)
+	searchService := searchservice.New(savedSearchRepo)
+	apiKeyService := apikeyservice.New(log, apiKeyRepo)
+	log.Debug("Services initialized")
+
+	streamFeed := streamservice.NewFeed(log, outboxRepo, streamService, cfg.Stream)
+
+	// stream isn't an outbox publisher, only one replica relays every message and the stream is fed on all of them
+	publishers := map[string]outboxservice.Publisher{"webhooks": webhookService}
+	switch cfg.Outbox.Publisher {
+	case "":
+	case "log":
+		publishers[cfg.Outbox.Publisher] = outboxservice.NewLogPublisher(log)
+	case "file":
+		filePublisher, err := outboxservice.NewFilePublisher(cfg.Outbox.FilePath)
+		if err != nil {
+			log.Error("Failed to initialize file publisher", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		defer filePublisher.Close()
+		publishers[cfg.Outbox.Publisher] = filePublisher
+	default:
+		log.Error("Unknown outbox publisher", slog.String("publisher", cfg.Outbox.Publisher))
+		os.Exit(1)
+	}
+	outboxRelay := outboxservice.New(log, outboxRepo, publishers, cfg.Outbox)
+	log.Debug("Outbox relay initialized", slog.String("publisher", cfg.Outbox.Publisher))
+
+	listener := postgresdb.NewListener(log, cfg.Postgres.DSN, cfg.Postgres.ListenerMinReconnect, cfg.Postgres.ListenerMaxReconnect)
+	err = listener.Subscribe(postgres.CarsChangesChannel, func(n postgresdb.Notification) {
+		if n.Reconnected {
+			cachedCarRepo.InvalidateAll()
+			return
+		}
+
+		change, err := postgresdb.ParseChange(n.Payload)
+		if err != nil {
+			log.Error("Failed to parse car change", slog.String("error", err.Error()))
+			cachedCarRepo.InvalidateAll()
+			return
+		}
+
+		cachedCarRepo.InvalidateCar(change.ID)
+	})
+	if err != nil {
+		log.Error("Failed to subscribe to car changes", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	// notifications only wake the feed up, it reads the outbox from its cursor, so missed ones delay events
+	// until the next poll at most
+	err = listener.Subscribe(postgres.OutboxMessagesChannel, func(n postgresdb.Notification) {
+		streamFeed.Notify()
+	})
+	if err != nil {
+		log.Error("Failed to subscribe to outbox messages", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	log.Debug("Postgres listener initialized")
+
+	go webhookService.Run(context.Background())
+	go carInfoClient.Run(context.Background(), cfg.CarsInfoApi.CachePruneInterval)
+	go outboxRelay.Run(context.Background())
+	go streamFeed.Run(context.Background())
+	go listener.Run(context.Background())
+
+	var tokenVerifier interface {
+		Verify(token string) (jwt.Claims, error)
+	}
+	if cfg.Auth.JWTSecret != "" || cfg.Auth.JWTPublicKeyFile != "" {
+		jwtConfig := jwt.Config{
+			Issuer:   cfg.Auth.JWTIssuer,
+			Audience: cfg.Auth.JWTAudience,
+			Leeway:   cfg.Auth.JWTLeeway,
+		}
+		if cfg.Auth.JWTSecret != "" {
+			jwtConfig.HMACSecret = []byte(cfg.Auth.JWTSecret)
+		}
+		if cfg.Auth.JWTPublicKeyFile != "" {
+			pemKey, err := os.ReadFile(cfg.Auth.JWTPublicKeyFile)
+			if err != nil {
+				log.Error("Failed to read jwt public key", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+
+			jwtConfig.RSAPublicKey, err = jwt.ParseRSAPublicKey(pemKey)
+			if err != nil {
+				log.Error("Failed to parse jwt public key", slog.String("error", err.Error()))
+				os.Exit(1)
+			}
+		}
+		tokenVerifier = jwt.NewVerifier(jwtConfig)
+	}
+	log.Debug("Token verifier initialized", slog.Bool("enabled", tokenVerifier != nil))
+
+	rateLimits := v1.RateLimits{TrustProxy: cfg.RateLimit.TrustProxy}
+	if cfg.RateLimit.IPRate > 0 {
+		rateLimits.IP = ratelimit.New(cfg.RateLimit.IPRate, cfg.RateLimit.IPBurst, cfg.RateLimit.MaxClients)
+	}
+	if cfg.RateLimit.ReadRate > 0 {
+		rateLimits.Read = ratelimit.New(cfg.RateLimit.ReadRate, cfg.RateLimit.ReadBurst, cfg.RateLimit.MaxClients)
+	}
+	if cfg.RateLimit.WriteRate > 0 {
+		rateLimits.Write = ratelimit.New(cfg.RateLimit.WriteRate, cfg.RateLimit.WriteBurst, cfg.RateLimit.MaxClients)
+	}
+
+	var spec *swag.Spec
+	if cfg.HTTP.DocsEnabled {
+		spec, err = apiSpec(cfg.HTTP)
+		if err != nil {
+			log.Error("Failed to initialize api spec", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+	log.Debug("Api spec initialized", slog.Bool("enabled", spec != nil))
+
+	var requestValidator *openapi.Validator
+	if cfg.HTTP.ValidateRequests {
+		requestValidator, err = openapi.NewValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+		if err != nil {
+			log.Error("Failed to initialize request validator", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+	log.Debug("Request validator initialized", slog.Bool("enabled", requestValidator != nil))
+
+	mux := v1.NewMux(log, v1.Deps{
+		CarService:              carService,
+		OwnerService:            ownerService,
+		CarInfoService:          carInfoService,
+		SearchService:           searchService,
+		WebhookService:          webhookService,
+		StreamService:           streamService,
+		CacheStatsProvider:      cachedCarRepo,
+		CarInfoStatusProvider:   breakerClient,
+		StreamHeartbeatInterval: cfg.Stream.HeartbeatInterval,
+		APIKeyService:           apiKeyService,
+		TokenVerifier:           tokenVerifier,
+		RateLimits:              rateLimits,
+		CORS:                    cfg.CORS,
+		APISpec:                 spec,
+		RequestValidator:        requestValidator,
+		MaxBodySize:             cfg.HTTP.MaxBodySize,
+	})
+	log.Debug("Mux initialized")
+
+	httpServer := &http.Server{
+		Addr:    cfg.HTTP.Address,
+		Handler: mux,
+	}
+
+	log.Info("Server started", slog.String("address", cfg.HTTP.Address))
+
+	if err := httpServer.ListenAndServe(); err != nil {
+		log.Error("Failed to start server", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	log.Error("server stopped")
+}
+
+// apiSpec returns copy of generated OpenAPI spec with host, base path and schemes of the service taken from cfg.
+func apiSpec(cfg config.HTTPConfig) (*swag.Spec, error) {
+	spec := *docs.SwaggerInfo
+
+	if cfg.PublicURL == "" {
+		host, port, err := net.SplitHostPort(cfg.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid http address %q: %w", cfg.Address, err)
+		}
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "localhost"
+		}
+
+		spec.Host = net.JoinHostPort(host, port)
+		return &spec, nil
+	}
+
+	u, err := url.Parse(cfg.PublicURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid public url %q", cfg.PublicURL)
+	}
+
+	spec.Host = u.Host
+	spec.BasePath = strings.TrimSuffix(u.Path, "/") + "/api/v1"
+	spec.Schemes = []string{u.Scheme}
+
+	return &spec, nil
+}
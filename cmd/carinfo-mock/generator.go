@@ -0,0 +1,42 @@
+package main
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
+)
+
+var (
+	models = map[string][]string{
+		"Lada":       {"Vesta", "Granta", "Niva", "Largus"},
+		"Kia":        {"Rio", "Ceed", "Sportage"},
+		"Hyundai":    {"Solaris", "Creta", "Tucson"},
+		"Toyota":     {"Camry", "Corolla", "RAV4"},
+		"Volkswagen": {"Polo", "Tiguan", "Passat"},
+	}
+	marks       = []string{"Lada", "Kia", "Hyundai", "Toyota", "Volkswagen"}
+	names       = []string{"Ivan", "Petr", "Sergey", "Anna", "Maria", "Olga"}
+	surnames    = []string{"Ivanov", "Petrov", "Sidorov", "Smirnov", "Kuznetsov", "Popov"}
+	patronymics = []string{"", "Ivanovich", "Petrovich", "Sergeevich"}
+)
+
+// generateCarInfo returns car derived from the number, so the same number always gets the same car.
+func generateCarInfo(regNumber string) carinfo.CarInfo {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(regNumber))
+	rnd := rand.New(rand.NewPCG(h.Sum64(), 0))
+
+	mark := marks[rnd.IntN(len(marks))]
+	return carinfo.CarInfo{
+		RegNumber: regNumber,
+		Mark:      mark,
+		Model:     models[mark][rnd.IntN(len(models[mark]))],
+		Year:      1995 + rnd.IntN(30),
+		Owner: carinfo.Owner{
+			Name:       names[rnd.IntN(len(names))],
+			Surname:    surnames[rnd.IntN(len(surnames))],
+			Patronymic: patronymics[rnd.IntN(len(patronymics))],
+		},
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/net/v1/middleware"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// carinfo-mock is a stand-in for the external car info API described in README.
+// It serves GET /info?regNum= from a seed file and/or generated data and can inject latency and errors.
+func main() {
+	var (
+		addr           = flag.String("addr", ":8081", "address to listen on")
+		env            = flag.String("env", "local", "logger environment: local, dev or prod")
+		seed           = flag.String("seed", "", "JSON or CSV file with cars, see carinfo.LoadDataset")
+		generate       = flag.Bool("generate", true, "generate cars for numbers missing in the seed file, otherwise answer 400")
+		latency        = flag.Duration("latency", 0, "delay of every answer")
+		jitter         = flag.Duration("jitter", 0, "random extra delay up to this value")
+		errorRate      = flag.Float64("error-rate", 0, "share of requests answered with 500, from 0 to 1")
+		badRequestRate = flag.Float64("bad-request-rate", 0, "share of requests answered with 400, from 0 to 1")
+	)
+	flag.Parse()
+
+	log, err := logger.New(*env)
+	if err != nil {
+		slog.Error("Failed to initialize logger", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	var dataset *carinfo.Dataset
+	if *seed != "" {
+		dataset, err = carinfo.LoadDataset(*seed)
+		if err != nil {
+			log.Error("Failed to load seed file", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		log.Info("Seed file loaded", slog.String("path", *seed))
+	}
+
+	s := &server{
+		log:            log,
+		dataset:        dataset,
+		generate:       *generate,
+		latency:        *latency,
+		jitter:         *jitter,
+		errorRate:      *errorRate,
+		badRequestRate: *badRequestRate,
+	}
+
+	mux := chi.NewMux()
+	mux.Use(chiMiddleware.RequestID)
+	mux.Use(middleware.Logger(log))
+	mux.Get("/info", s.getInfo)
+
+	log.Info("Mock car info api started", slog.String("address", *addr))
+
+	if err = http.ListenAndServe(*addr, mux); err != nil {
+		log.Error("Failed to start server", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+type server struct {
+	log            *slog.Logger
+	dataset        *carinfo.Dataset
+	generate       bool
+	latency        time.Duration
+	jitter         time.Duration
+	errorRate      float64
+	badRequestRate float64
+}
+
+func (s *server) getInfo(w http.ResponseWriter, r *http.Request) {
+	log := s.log.With(slog.String("request_id", chiMiddleware.GetReqID(r.Context())))
+
+	delay := s.latency
+	if s.jitter > 0 {
+		delay += rand.N(s.jitter)
+	}
+	if delay > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+
+	switch n := rand.Float64(); {
+	case n < s.errorRate:
+		log.Debug("injected internal server error")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	case n < s.errorRate+s.badRequestRate:
+		log.Debug("injected bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	regNumber := r.URL.Query().Get("regNum")
+	if regNumber == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	info, err := s.carInfo(r.Context(), regNumber)
+	if err != nil {
+		if errors.Is(err, carinfo.ErrNotFound) {
+			log.Debug("unknown car", slog.String("reg_number", regNumber))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		log.Error("failed to get car info", slog.String("error", err.Error()))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	body, err := json.Marshal(info)
+	if err != nil {
+		log.Error("failed to marshal car info", slog.String("error", err.Error()))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+func (s *server) carInfo(ctx context.Context, regNumber string) (carinfo.CarInfo, error) {
+	if s.dataset != nil {
+		info, err := s.dataset.GetCarInfoByRegNumber(ctx, regNumber)
+		if err == nil || !s.generate {
+			return info, err
+		}
+	}
+
+	if !s.generate {
+		return carinfo.CarInfo{}, carinfo.ErrNotFound
+	}
+
+	return generateCarInfo(regNumber), nil
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/sdk"
+)
+
+func runCars(c *sdk.Client, args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: expected add, list, update or delete", errUsage)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "add":
+		return addCars(ctx, c, args[1:], stdout, stderr)
+	case "list":
+		return listCars(ctx, c, args[1:], stdout, stderr)
+	case "update":
+		return updateCar(ctx, c, args[1:], stderr)
+	case "delete":
+		return deleteCars(ctx, c, args[1:], stderr)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+}
+
+func addCars(ctx context.Context, c *sdk.Client, args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("cars add", stderr)
+	bypassCache := fs.Bool("bypass-cache", false, "ask the car info API even if the answer is cached")
+	output := fs.String("output", outputTable, "output format: table, json or csv")
+
+	regNumbers, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(regNumbers) == 0 {
+		return fmt.Errorf("%w: at least one registration number is required", errUsage)
+	}
+
+	statuses, err := c.AddCars(ctx, sdk.AddCarsInput{RegNumbers: regNumbers, BypassCache: *bypassCache})
+	if err != nil {
+		return err
+	}
+
+	return printStatuses(stdout, *output, statuses)
+}
+
+func listCars(ctx context.Context, c *sdk.Client, args []string, stdout, stderr io.Writer) error {
+	var filters stringsFlag
+
+	fs := newFlagSet("cars list", stderr)
+	fs.Var(&filters, "filter", "field filter FIELD=[OP:]VALUE, e.g. year=gt:2010, can be repeated, all filters must match")
+	expr := fs.String("expr", "", "boolean filter expression, e.g. \"mark eq Lada or year gt 2015\"")
+	limit := fs.Int("limit", 0, "maximum number of cars, 0 means no limit")
+	offset := fs.Int("offset", 0, "number of cars to skip")
+	sortBy := fs.String("sort", "", "comma separated fields to sort by before pagination, prefix with - for descending order")
+	output := fs.String("output", outputTable, "output format: table, json or csv")
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("%w: unexpected arguments %v", errUsage, rest)
+	}
+
+	opts := sdk.ListCarsOptions{
+		Limit:  *limit,
+		Offset: *offset,
+		Fields: make(map[string][]string, len(filters)),
+		Filter: *expr,
+		Sort:   *sortBy,
+	}
+	for _, f := range filters {
+		field, value, ok := strings.Cut(f, "=")
+		if !ok || field == "" {
+			return fmt.Errorf("%w: invalid filter %q, expected FIELD=[OP:]VALUE", errUsage, f)
+		}
+		opts.Fields[field] = append(opts.Fields[field], value)
+	}
+
+	cars, err := c.ListCars(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return printCars(stdout, *output, cars)
+}
+
+func updateCar(ctx context.Context, c *sdk.Client, args []string, stderr io.Writer) error {
+	var input sdk.UpdateCarInput
+
+	fs := newFlagSet("cars update", stderr)
+	fs.StringVar(&input.Mark, "mark", "", "new mark")
+	fs.StringVar(&input.Model, "model", "", "new model")
+	fs.IntVar(&input.Year, "year", 0, "new year")
+	fs.StringVar(&input.OwnerName, "owner-name", "", "new owner name")
+	fs.StringVar(&input.OwnerSurname, "owner-surname", "", "new owner surname")
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		return fmt.Errorf("%w: exactly one registration number is required", errUsage)
+	}
+	if input == (sdk.UpdateCarInput{}) {
+		return fmt.Errorf("%w: nothing to update", errUsage)
+	}
+
+	if err = c.UpdateCar(ctx, rest[0], input); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "car %s updated\n", rest[0])
+	return nil
+}
+
+func deleteCars(ctx context.Context, c *sdk.Client, args []string, stderr io.Writer) error {
+	fs := newFlagSet("cars delete", stderr)
+
+	regNumbers, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(regNumbers) == 0 {
+		return fmt.Errorf("%w: at least one registration number is required", errUsage)
+	}
+
+	for _, regNumber := range regNumbers {
+		if err = c.DeleteCar(ctx, regNumber); err != nil {
+			return fmt.Errorf("%s: %w", regNumber, err)
+		}
+		fmt.Fprintf(stderr, "car %s deleted\n", regNumber)
+	}
+
+	return nil
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseFlags parses flags placed anywhere among positional arguments and returns the positional ones.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %w", errUsage, err)
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/sdk"
+)
+
+const (
+	serverEnv     = "CARSCTL_SERVER"
+	apiKeyEnv     = "CARSCTL_API_KEY"
+	defaultServer = "http://localhost:8080/api/v1"
+)
+
+// Exit codes.
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitBadRequest
+	exitUnauthorized
+	exitTooManyRequests
+	exitServerError
+)
+
+var errUsage = errors.New("usage error")
+
+const usage = `carsctl manages the cars catalog.
+
+Usage:
+  carsctl [--server URL] [--api-key KEY] cars add [--bypass-cache] [--output FORMAT] REG_NUMBER...
+  carsctl [--server URL] [--api-key KEY] cars list [--filter FIELD=[OP:]VALUE]... [--expr EXPRESSION] [--limit N] [--offset N] [--sort [-]FIELD[,...]] [--output FORMAT]
+  carsctl [--server URL] [--api-key KEY] cars update REG_NUMBER [--mark MARK] [--model MODEL] [--year YEAR] [--owner-name NAME] [--owner-surname SURNAME]
+  carsctl [--server URL] [--api-key KEY] cars delete REG_NUMBER...
+
+The server URL is taken from --server or ` + serverEnv + `, default is ` + defaultServer + `.
+The API key is taken from --api-key or ` + apiKeyEnv + `.
+FORMAT is table, json or csv.
+
+Exit codes: 0 success, 1 error, 2 usage error, 3 bad request, 4 unauthorized or forbidden,
+5 too many requests, 6 server error.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("carsctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	server := fs.String("server", "", "server URL including API base path")
+	apiKey := fs.String("api-key", "", "API key")
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if *server == "" {
+		*server = os.Getenv(serverEnv)
+	}
+	if *server == "" {
+		*server = defaultServer
+	}
+
+	if *apiKey == "" {
+		*apiKey = os.Getenv(apiKeyEnv)
+	}
+
+	c, err := newClient(*server, *apiKey)
+	if err != nil {
+		fmt.Fprintf(stderr, "carsctl: %v\n", err)
+		return exitUsage
+	}
+
+	args = fs.Args()
+	if len(args) == 0 || args[0] != "cars" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	err = runCars(c, args[1:], stdout, stderr)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(stderr, "carsctl: %v\n", err)
+	}
+
+	return exitCode(err)
+}
+
+func newClient(server, apiKey string) (*sdk.Client, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return nil, fmt.Errorf("invalid server url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid server url %q, expected e.g. %s", server, defaultServer)
+	}
+
+	basePath := strings.TrimSuffix(u.Path, "/")
+	if basePath == "" {
+		basePath = "/api/v1"
+	}
+
+	var auth client.Authenticator
+	if apiKey != "" {
+		auth = client.NewAPIKeyAuth("X-API-Key", apiKey)
+	}
+
+	httpClient := client.NewHTTPClient(u.Host, basePath, u.Scheme, http.Client{}, client.RetryPolicy{}, auth)
+	return sdk.New(httpClient), nil
+}
+
+func exitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.Is(err, sdk.ErrBadRequest):
+		return exitBadRequest
+	case errors.Is(err, sdk.ErrUnauthorized), errors.Is(err, sdk.ErrForbidden):
+		return exitUnauthorized
+	case errors.Is(err, sdk.ErrTooManyRequests):
+		return exitTooManyRequests
+	}
+
+	var apiErr *sdk.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode >= http.StatusInternalServerError {
+		return exitServerError
+	}
+
+	return exitError
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/sdk"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+var carHeader = []string{"REG_NUMBER", "MARK", "MODEL", "YEAR", "OWNER_NAME", "OWNER_SURNAME", "SOURCE"}
+
+func printCars(w io.Writer, format string, cars []sdk.Car) error {
+	if format == outputJSON {
+		if cars == nil {
+			cars = []sdk.Car{}
+		}
+		return printJSON(w, cars)
+	}
+
+	rows := make([][]string, 0, len(cars))
+	for _, car := range cars {
+		year := ""
+		if car.Year != 0 {
+			year = strconv.Itoa(car.Year)
+		}
+		rows = append(rows, []string{car.RegNumber, car.Mark, car.Model, year, car.OwnerName, car.OwnerSurname, car.Source})
+	}
+
+	return printRows(w, format, carHeader, rows)
+}
+
+func printStatuses(w io.Writer, format string, statuses map[string]string) error {
+	if format == outputJSON {
+		return printJSON(w, statuses)
+	}
+
+	regNumbers := make([]string, 0, len(statuses))
+	for regNumber := range statuses {
+		regNumbers = append(regNumbers, regNumber)
+	}
+	slices.Sort(regNumbers)
+
+	rows := make([][]string, 0, len(regNumbers))
+	for _, regNumber := range regNumbers {
+		rows = append(rows, []string{regNumber, statuses[regNumber]})
+	}
+
+	return printRows(w, format, []string{"REG_NUMBER", "STATUS"}, rows)
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func printRows(w io.Writer, format string, header []string, rows [][]string) error {
+	switch format {
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, row := range append([][]string{header}, rows...) {
+			for i, cell := range row {
+				if i > 0 {
+					fmt.Fprint(tw, "\t")
+				}
+				fmt.Fprint(tw, cell)
+			}
+			fmt.Fprintln(tw)
+		}
+		return tw.Flush()
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(rows); err != nil {
+			return err
+		}
+		return cw.Error()
+	default:
+		return fmt.Errorf("%w: unknown output format %q", errUsage, format)
+	}
+}
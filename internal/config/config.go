@@ -1,8 +1,10 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -10,10 +12,18 @@ import (
 )
 
 type Config struct {
-	Postgres    PostgresConfig
-	HTTP        HTTPConfig
-	CarsInfoApi CarsInfoApiConfig
-	Env         string `env:"ENV"`
+	Postgres         PostgresConfig
+	HTTP             HTTPConfig
+	CarsInfoApi      CarsInfoApiConfig
+	CarInfoProviders CarInfoProvidersConfig
+	Webhooks         WebhooksConfig
+	Stream           StreamConfig
+	Outbox           OutboxConfig
+	Cache            CacheConfig
+	Auth             AuthConfig
+	RateLimit        RateLimitConfig
+	CORS             CORSConfig
+	Env              string `env:"ENV"`
 }
 
 type PostgresConfig struct {
@@ -23,6 +33,9 @@ type PostgresConfig struct {
 	Password string `env:"POSTGRES_PASS"`
 	Database string `env:"POSTGRES_DB"`
 	DSN      string
+
+	ListenerMinReconnect time.Duration `env:"POSTGRES_LISTENER_MIN_RECONNECT" env-default:"1s"`
+	ListenerMaxReconnect time.Duration `env:"POSTGRES_LISTENER_MAX_RECONNECT" env-default:"1m"`
 }
 
 type HTTPConfig struct {
@@ -31,12 +44,148 @@ type HTTPConfig struct {
 	Address     string
 	Timeout     time.Duration `env:"HTTP_TIMEOUT"`
 	IdleTimeout time.Duration `env:"HTTP_IDLE_TIMEOUT"`
+	// PublicURL is address clients reach the service at, e.g. https://cars.example.com, it fills host and base path
+	// of the served OpenAPI spec. If empty, Address is used.
+	PublicURL   string `env:"HTTP_PUBLIC_URL"`
+	DocsEnabled bool   `env:"HTTP_DOCS_ENABLED" env-default:"true"`
+	// ValidateRequests rejects requests which don't match the OpenAPI spec before they reach handlers.
+	ValidateRequests bool `env:"HTTP_VALIDATE_REQUESTS" env-default:"true"`
+	// MaxBodySize limits size of request bodies in bytes, zero means no limit.
+	MaxBodySize int64 `env:"HTTP_MAX_BODY_SIZE" env-default:"1048576"`
 }
 
 type CarsInfoApiConfig struct {
 	Host     string `env:"CARS_INFO_API_HOST"`
 	BasePath string `env:"CARS_INFO_API_BASE_PATH"`
 	Scheme   string `env:"CARS_INFO_API_SCHEME"`
+	// Concurrency limits number of simultaneous requests made for a single POST /cars.
+	Concurrency int `env:"CARS_INFO_API_CONCURRENCY" env-default:"8"`
+
+	RetryMaxAttempts int           `env:"CARS_INFO_API_RETRY_MAX_ATTEMPTS" env-default:"3"`
+	RetryBaseBackoff time.Duration `env:"CARS_INFO_API_RETRY_BASE_BACKOFF" env-default:"200ms"`
+	RetryMaxBackoff  time.Duration `env:"CARS_INFO_API_RETRY_MAX_BACKOFF" env-default:"5s"`
+	RetryStatuses    []int         `env:"CARS_INFO_API_RETRY_STATUSES" env-default:"429,502,503,504" env-separator:","`
+
+	BreakerFailureThreshold int           `env:"CARS_INFO_API_BREAKER_FAILURE_THRESHOLD" env-default:"5"`
+	BreakerOpenTimeout      time.Duration `env:"CARS_INFO_API_BREAKER_OPEN_TIMEOUT" env-default:"30s"`
+	BreakerHalfOpenRequests int           `env:"CARS_INFO_API_BREAKER_HALF_OPEN_REQUESTS" env-default:"1"`
+
+	// APIKey is sent in APIKeyHeader. TokenURL enables bearer tokens got with client credentials grant instead.
+	APIKey            string `env:"CARS_INFO_API_KEY"`
+	APIKeyHeader      string `env:"CARS_INFO_API_KEY_HEADER" env-default:"X-API-Key"`
+	TokenURL          string `env:"CARS_INFO_API_TOKEN_URL"`
+	TokenClientID     string `env:"CARS_INFO_API_TOKEN_CLIENT_ID"`
+	TokenClientSecret string `env:"CARS_INFO_API_TOKEN_CLIENT_SECRET"`
+	TokenScope        string `env:"CARS_INFO_API_TOKEN_SCOPE"`
+
+	// TLSCertFile and TLSKeyFile enable mutual TLS and must be set together, TLSCAFile replaces system roots
+	// for the API certificate. Credentials and TLS are used for every car info provider API.
+	TLSCertFile string `env:"CARS_INFO_API_TLS_CERT_FILE"`
+	TLSKeyFile  string `env:"CARS_INFO_API_TLS_KEY_FILE"`
+	TLSCAFile   string `env:"CARS_INFO_API_TLS_CA_FILE"`
+
+	// CacheNegativeTTL is used for numbers the API answered with "bad request".
+	CacheTTL         time.Duration `env:"CARS_INFO_API_CACHE_TTL" env-default:"24h"`
+	CacheNegativeTTL time.Duration `env:"CARS_INFO_API_CACHE_NEGATIVE_TTL" env-default:"5m"`
+	CacheSize        int           `env:"CARS_INFO_API_CACHE_SIZE" env-default:"10000"`
+	// CachePersist stores cached answers in postgres, so they survive restarts.
+	CachePersist bool `env:"CARS_INFO_API_CACHE_PERSIST" env-default:"false"`
+	// CachePruneInterval is how often expired answers are deleted from postgres.
+	CachePruneInterval time.Duration `env:"CARS_INFO_API_CACHE_PRUNE_INTERVAL" env-default:"1h"`
+}
+
+type CarInfoProvidersConfig struct {
+	// Order lists providers asked for car info by priority: "primary", "secondary" and "dataset".
+	Order []string `env:"CARS_INFO_PROVIDERS" env-default:"primary" env-separator:","`
+
+	SecondaryHost     string `env:"CARS_INFO_SECONDARY_API_HOST"`
+	SecondaryBasePath string `env:"CARS_INFO_SECONDARY_API_BASE_PATH"`
+	SecondaryScheme   string `env:"CARS_INFO_SECONDARY_API_SCHEME" env-default:"https"`
+	SecondaryMethod   string `env:"CARS_INFO_SECONDARY_API_METHOD" env-default:"/info"`
+
+	// DatasetPath is JSON or CSV file with known cars.
+	DatasetPath string `env:"CARS_INFO_DATASET_PATH"`
+}
+
+type WebhooksConfig struct {
+	MaxAttempts  int           `env:"WEBHOOKS_MAX_ATTEMPTS" env-default:"8"`
+	BaseBackoff  time.Duration `env:"WEBHOOKS_BASE_BACKOFF" env-default:"1s"`
+	MaxBackoff   time.Duration `env:"WEBHOOKS_MAX_BACKOFF" env-default:"10m"`
+	Timeout      time.Duration `env:"WEBHOOKS_TIMEOUT" env-default:"10s"`
+	PollInterval time.Duration `env:"WEBHOOKS_POLL_INTERVAL" env-default:"1s"`
+	BatchSize    int           `env:"WEBHOOKS_BATCH_SIZE" env-default:"50"`
+}
+
+type StreamConfig struct {
+	BufferSize        int           `env:"STREAM_BUFFER_SIZE" env-default:"1000"`
+	HeartbeatInterval time.Duration `env:"STREAM_HEARTBEAT_INTERVAL" env-default:"15s"`
+	// FeedPollInterval is how often the outbox is read for new events if no notification comes.
+	FeedPollInterval time.Duration `env:"STREAM_FEED_POLL_INTERVAL" env-default:"1s"`
+	// FeedGapTimeout is how long an outbox message with a skipped id is waited for, it should be longer
+	// than transactions writing to the outbox.
+	FeedGapTimeout time.Duration `env:"STREAM_FEED_GAP_TIMEOUT" env-default:"30s"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" env-default:"500ms"`
+	BatchSize    int           `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	MaxAttempts  int           `env:"OUTBOX_MAX_ATTEMPTS" env-default:"10"`
+	BaseBackoff  time.Duration `env:"OUTBOX_BASE_BACKOFF" env-default:"1s"`
+	MaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF" env-default:"10m"`
+	// Lease is how long claimed messages are hidden from other relays while they are published.
+	Lease time.Duration `env:"OUTBOX_LEASE" env-default:"1m"`
+	// Retention is how long published messages are kept, zero keeps them forever. Dead messages are never deleted.
+	Retention     time.Duration `env:"OUTBOX_RETENTION" env-default:"168h"`
+	PruneInterval time.Duration `env:"OUTBOX_PRUNE_INTERVAL" env-default:"1h"`
+	// Publisher is an additional built-in publisher of events: "log", "file" or empty for none.
+	Publisher string `env:"OUTBOX_PUBLISHER"`
+	FilePath  string `env:"OUTBOX_FILE_PATH" env-default:"events.jsonl"`
+}
+
+type AuthConfig struct {
+	// JWTSecret enables HS256 tokens, JWTPublicKeyFile is PEM encoded key or certificate enabling RS256 tokens.
+	JWTSecret        string `env:"AUTH_JWT_SECRET"`
+	JWTPublicKeyFile string `env:"AUTH_JWT_PUBLIC_KEY_FILE"`
+	// JWTIssuer and JWTAudience are checked against iss and aud claims if set.
+	JWTIssuer   string        `env:"AUTH_JWT_ISSUER"`
+	JWTAudience string        `env:"AUTH_JWT_AUDIENCE"`
+	JWTLeeway   time.Duration `env:"AUTH_JWT_LEEWAY" env-default:"30s"`
+}
+
+type RateLimitConfig struct {
+	// ReadRate and WriteRate are requests per second allowed to a client for GET and the other requests,
+	// zero disables limiting. Bursts are numbers of requests allowed at once.
+	ReadRate   float64 `env:"RATE_LIMIT_READ_RATE" env-default:"20"`
+	ReadBurst  int     `env:"RATE_LIMIT_READ_BURST" env-default:"40"`
+	WriteRate  float64 `env:"RATE_LIMIT_WRITE_RATE" env-default:"2"`
+	WriteBurst int     `env:"RATE_LIMIT_WRITE_BURST" env-default:"5"`
+	// IPRate and IPBurst limit all requests of every IP address before authentication, zero disables limiting.
+	IPRate  float64 `env:"RATE_LIMIT_IP_RATE" env-default:"50"`
+	IPBurst int     `env:"RATE_LIMIT_IP_BURST" env-default:"100"`
+	// MaxClients is number of tracked clients after which clients with refilled limits are forgotten. Clients
+	// which have used up some of their limits are tracked until the limits refill even above MaxClients.
+	MaxClients int `env:"RATE_LIMIT_MAX_CLIENTS" env-default:"10000"`
+	// TrustProxy takes client IP from X-Forwarded-For and X-Real-IP headers.
+	TrustProxy bool `env:"RATE_LIMIT_TRUST_PROXY" env-default:"false"`
+}
+
+type CORSConfig struct {
+	// AllowedOrigins are origins or patterns with a single wildcard, e.g. https://*.example.com, "*" allows any origin
+	// and can't be used with AllowCredentials.
+	AllowedOrigins []string `env:"CORS_ALLOWED_ORIGINS" env-default:"https://editor.swagger.io" env-separator:","`
+	AllowedMethods []string `env:"CORS_ALLOWED_METHODS" env-default:"GET,POST,PUT,DELETE" env-separator:","`
+	// AllowedHeaders are request headers browsers may send, "*" allows any header.
+	AllowedHeaders   []string      `env:"CORS_ALLOWED_HEADERS" env-default:"Authorization,Content-Type,X-API-Key,Last-Event-ID" env-separator:","`
+	ExposedHeaders   []string      `env:"CORS_EXPOSED_HEADERS" env-default:"RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,RateLimit-Policy,Retry-After" env-separator:","`
+	AllowCredentials bool          `env:"CORS_ALLOW_CREDENTIALS" env-default:"false"`
+	MaxAge           time.Duration `env:"CORS_MAX_AGE" env-default:"10m"`
+}
+
+type CacheConfig struct {
+	TTL time.Duration `env:"CACHE_TTL" env-default:"30s"`
+	// CarsSize and ListsSize limit number of cached cars and GetCars pages, zero disables caching.
+	CarsSize  int `env:"CACHE_CARS_SIZE" env-default:"10000"`
+	ListsSize int `env:"CACHE_LISTS_SIZE" env-default:"1000"`
 }
 
 func MustLoad() *Config {
@@ -56,5 +205,30 @@ func MustLoad() *Config {
 
 	cfg.HTTP.Address = fmt.Sprintf("%s:%s", cfg.HTTP.Host, cfg.HTTP.Port)
 
+	err = cfg.Validate()
+	if err != nil {
+		log.Fatalf("Invalid config: %s", err.Error())
+	}
+
 	return &cfg
 }
+
+// Validate reports combinations of settings the service can't work with.
+func (c *Config) Validate() error {
+	err := c.CORS.Validate()
+	if err != nil {
+		return fmt.Errorf("cors: %w", err)
+	}
+
+	return nil
+}
+
+// Validate rejects allowing any origin with credentials. Browsers don't send credentials to "*", and echoing
+// every origin instead would let any site make authenticated requests.
+func (c CORSConfig) Validate() error {
+	if c.AllowCredentials && slices.Contains(c.AllowedOrigins, "*") {
+		return errors.New(`CORS_ALLOWED_ORIGINS can't contain "*" when CORS_ALLOW_CREDENTIALS is true`)
+	}
+
+	return nil
+}
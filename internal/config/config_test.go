@@ -0,0 +1,48 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/config"
+)
+
+func TestCORSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.CORSConfig
+		wantErr bool
+	}{
+		{
+			name: "any origin without credentials",
+			cfg:  config.CORSConfig{AllowedOrigins: []string{"*"}},
+		},
+		{
+			name: "listed origins with credentials",
+			cfg:  config.CORSConfig{AllowedOrigins: []string{"https://editor.swagger.io", "https://*.example.com"}, AllowCredentials: true},
+		},
+		{
+			name:    "any origin with credentials",
+			cfg:     config.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			wantErr: true,
+		},
+		{
+			name:    "any origin among others with credentials",
+			cfg:     config.CORSConfig{AllowedOrigins: []string{"https://editor.swagger.io", "*"}, AllowCredentials: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			cfg := config.Config{CORS: tt.cfg}
+			if err = cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Config.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
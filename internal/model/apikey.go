@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type APIKey struct {
+	ID         int        `db:"id" json:"id"`
+	Name       string     `db:"name" json:"name"`
+	Prefix     string     `db:"prefix" json:"prefix"`
+	Hash       []byte     `db:"hash" json:"-"`
+	Scopes     []string   `db:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time `db:"expires_at" json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"createdAt"`
+}
+
+// Active reports whether the key is neither revoked nor expired at now.
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+func (k APIKey) Identity() Identity {
+	return Identity{
+		Subject: "apikey:" + k.Prefix,
+		Scopes:  k.Scopes,
+	}
+}
@@ -1,10 +1,11 @@
 package model
 
 type Car struct {
-	RegistrationNumber string `db:"registration_number" json:"regNumber"`
-	Mark               string `db:"mark" json:"mark"`
-	Model              string `db:"model" json:"model"`
-	Year               int    `db:"year" json:"year,omitempty"`
-	OwnerName          string `db:"owner_name" json:"ownerName"`
-	OwnerSurname       string `db:"owner_surname" json:"ownerSurname"`
+	RegistrationNumber string `db:"registration_number" json:"regNumber" filter:"string"`
+	Mark               string `db:"mark" json:"mark" filter:"string"`
+	Model              string `db:"model" json:"model" filter:"string"`
+	Year               int    `db:"year" json:"year,omitempty" filter:"int"`
+	OwnerName          string `db:"owner_name" json:"ownerName" filter:"string"`
+	OwnerSurname       string `db:"owner_surname" json:"ownerSurname" filter:"string"`
+	Source             string `db:"source" json:"source,omitempty" filter:"string"`
 }
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+const (
+	EventCarCreated   = "car.created"
+	EventCarUpdated   = "car.updated"
+	EventCarDeleted   = "car.deleted"
+	EventOwnerCreated = "owner.created"
+
+	AggregateCar   = "car"
+	AggregateOwner = "owner"
+
+	OutboxStatusPending   = "pending"
+	OutboxStatusPublished = "published"
+	OutboxStatusDead      = "dead"
+)
+
+// EventTypes lists all event types that can be published.
+var EventTypes = []string{EventCarCreated, EventCarUpdated, EventCarDeleted, EventOwnerCreated}
+
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Car        *Car      `json:"car,omitempty"`
+	Owner      *Owner    `json:"owner,omitempty"`
+}
+
+// NewCarEvent returns car event of the given type with random id and current time.
+func NewCarEvent(type_ string, car Car) Event {
+	event := newEvent(type_)
+	event.Car = &car
+	return event
+}
+
+// NewOwnerEvent returns owner event of the given type with random id and current time.
+func NewOwnerEvent(type_ string, owner Owner) Event {
+	event := newEvent(type_)
+	event.Owner = &owner
+	return event
+}
+
+func newEvent(type_ string) Event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return Event{
+		ID:         hex.EncodeToString(id),
+		Type:       type_,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// Aggregate returns type and id of the entity the event is about. Events of one aggregate must be published in order.
+func (e Event) Aggregate() (string, string) {
+	switch {
+	case e.Car != nil:
+		return AggregateCar, e.Car.RegistrationNumber
+	case e.Owner != nil:
+		return AggregateOwner, fmt.Sprintf("%s %s", e.Owner.Name, e.Owner.Surname)
+	}
+	return "", ""
+}
+
+// OutboxMessage is an event stored in the same transaction as the change that caused it. PublishedTo lists names
+// of publishers which already published it, so retries skip them.
+type OutboxMessage struct {
+	ID            int64      `db:"id"`
+	AggregateType string     `db:"aggregate_type"`
+	AggregateID   string     `db:"aggregate_id"`
+	EventType     string     `db:"event_type"`
+	Payload       []byte     `db:"payload"`
+	Status        string     `db:"status"`
+	Attempts      int        `db:"attempts"`
+	LastError     string     `db:"last_error"`
+	PublishedTo   []string   `db:"published_to"`
+	NextAttemptAt time.Time  `db:"next_attempt_at"`
+	CreatedAt     time.Time  `db:"created_at"`
+	PublishedAt   *time.Time `db:"published_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "slices"
+
+const (
+	ScopeCarsRead      = "cars:read"
+	ScopeCarsWrite     = "cars:write"
+	ScopeCarsDelete    = "cars:delete"
+	ScopeOwnersWrite   = "owners:write"
+	ScopeSearchesWrite = "searches:write"
+	// ScopeAdmin grants every scope, including management of API keys and webhooks.
+	ScopeAdmin = "admin"
+)
+
+// Scopes lists all scopes that can be granted.
+var Scopes = []string{ScopeCarsRead, ScopeCarsWrite, ScopeCarsDelete, ScopeOwnersWrite, ScopeSearchesWrite, ScopeAdmin}
+
+// Identity is the authenticated client of a request, either an API key or a JWT subject.
+type Identity struct {
+	Subject string
+	Scopes  []string
+}
+
+// HasScope reports whether the identity is granted scope. Admins are granted every scope.
+func (i Identity) HasScope(scope string) bool {
+	return slices.Contains(i.Scopes, scope) || slices.Contains(i.Scopes, ScopeAdmin)
+}
@@ -1,7 +1,7 @@
 package model
 
 type Owner struct {
-	Name       string `json:"name"`
-	Surname    string `json:"surname"`
-	Patronymic string `json:"patronymic,omitempty"`
+	Name       string `db:"name" json:"name" filter:"string"`
+	Surname    string `db:"surname" json:"surname" filter:"string"`
+	Patronymic string `db:"patronymic" json:"patronymic,omitempty" filter:"string"`
 }
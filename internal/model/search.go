@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// SavedSearch is a named combination of GET /cars parameters. Fields map filter fields to their values,
+// every value is either a plain value or "operator:value", like in the url query.
+type SavedSearch struct {
+	ID        int                 `db:"id" json:"id"`
+	Name      string              `db:"name" json:"name"`
+	Filter    string              `db:"filter" json:"filter,omitempty"`
+	Fields    map[string][]string `db:"fields" json:"fields,omitempty"`
+	Sort      string              `db:"sort" json:"sort,omitempty"`
+	Limit     int                 `db:"page_limit" json:"limit,omitempty"`
+	Offset    int                 `db:"page_offset" json:"offset,omitempty"`
+	CreatedAt time.Time           `db:"created_at" json:"createdAt"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import "time"
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+)
+
+type Webhook struct {
+	ID        int       `db:"id" json:"id"`
+	URL       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"secret,omitempty"`
+	Events    []string  `db:"events" json:"events"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// Accepts reports whether the webhook is subscribed to the event type. Webhook without events accepts all of them.
+func (w Webhook) Accepts(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+
+	for _, event := range w.Events {
+		if event == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type WebhookDelivery struct {
+	ID             int       `db:"id" json:"id"`
+	WebhookID      int       `db:"webhook_id" json:"webhookId"`
+	EventID        string    `db:"event_id" json:"eventId"`
+	EventType      string    `db:"event_type" json:"eventType"`
+	Payload        []byte    `db:"payload" json:"-"`
+	Status         string    `db:"status" json:"status"`
+	Attempts       int       `db:"attempts" json:"attempts"`
+	ResponseStatus int       `db:"response_status" json:"responseStatus,omitempty"`
+	LastError      string    `db:"last_error" json:"lastError,omitempty"`
+	NextAttemptAt  time.Time `db:"next_attempt_at" json:"nextAttemptAt"`
+	CreatedAt      time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updatedAt"`
+}
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/apikeyservice"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type apiKeyService interface {
+	CreateKey(ctx context.Context, input apikeyservice.CreateKeyInput) (model.APIKey, string, error)
+	GetKeys(ctx context.Context) ([]model.APIKey, error)
+	RevokeKey(ctx context.Context, id int) error
+}
+
+type APIKeyHandler struct {
+	apiKeyService apiKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService apiKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+type CreateAPIKeyInput struct {
+	Name   string   `json:"name" validate:"required" minLength:"1"`
+	Scopes []string `json:"scopes,omitempty" enums:"cars:read,cars:write,cars:delete,owners:write,searches:write,admin"`
+	// ExpiresAt is RFC 3339 time, keys without it never expire.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" format:"date-time"`
+}
+
+type CreateAPIKeyResponse struct {
+	response.Response
+	APIKey model.APIKey `json:"apiKey"`
+	// Key is the only time the plain key is shown.
+	Key string `json:"key"`
+}
+
+type GetAPIKeysResponse struct {
+	response.Response
+	APIKeys []model.APIKey `json:"apiKeys"`
+}
+
+// CreateAPIKey
+// @Summary Create API key
+// @Tags admin
+// @Description Create API key. The key is returned only once, the service keeps just its hash.
+// @ID create-api-key
+// @Accept json
+// @Produce json
+// @Param input body CreateAPIKeyInput true "api key"
+// @Success 200 {object} CreateAPIKeyResponse
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/keys [post]
+func (h *APIKeyHandler) CreateAPIKey(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "CreateAPIKey"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var input CreateAPIKeyInput
+		if err := render.DecodeJSON(r.Body, &input); err != nil {
+			log.Info("request with wrong body")
+
+			renderResponse(w, r, response.BadRequest(requestWithWrongBody), http.StatusBadRequest)
+			return
+		}
+		log.Debug("input", slog.String("name", input.Name), slog.Any("scopes", input.Scopes))
+
+		if input.Name == "" {
+			log.Info("empty api key name")
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - name", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		for _, scope := range input.Scopes {
+			if !slices.Contains(model.Scopes, scope) {
+				log.Info("unknown scope", slog.String("scope", scope))
+
+				renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - scopes", invalidParameter)), http.StatusBadRequest)
+				return
+			}
+		}
+
+		if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+			log.Info("api key expires in the past", slog.Time("expires_at", *input.ExpiresAt))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - expiresAt", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		key, value, err := h.apiKeyService.CreateKey(r.Context(), apikeyservice.CreateKeyInput{
+			Name:      input.Name,
+			Scopes:    input.Scopes,
+			ExpiresAt: input.ExpiresAt,
+		})
+		if err != nil {
+			log.Error("failed to create api key", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("api key created", slog.Int("id", key.ID), slog.String("name", key.Name))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, CreateAPIKeyResponse{Response: response.OK(), APIKey: key, Key: value})
+		return
+	}
+}
+
+// GetAPIKeys
+// @Summary Get API keys
+// @Tags admin
+// @Description Get all API keys including revoked and expired ones
+// @ID get-api-keys
+// @Accept json
+// @Produce json
+// @Success 200 {object} GetAPIKeysResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/keys [get]
+func (h *APIKeyHandler) GetAPIKeys(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetAPIKeys"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		keys, err := h.apiKeyService.GetKeys(r.Context())
+		if err != nil {
+			log.Error("failed to get api keys", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("api keys found", slog.Int("api_keys_count", len(keys)))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, GetAPIKeysResponse{Response: response.OK(), APIKeys: keys})
+		return
+	}
+}
+
+// RevokeAPIKey
+// @Summary Revoke API key
+// @Tags admin
+// @Description Revoke API key, requests with it are rejected from now on
+// @ID revoke-api-key
+// @Accept json
+// @Produce json
+// @Param id path int true "api key id"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /admin/keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "RevokeAPIKey"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Info("invalid api key id", slog.String("id", chi.URLParam(r, "id")))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - id", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		err = h.apiKeyService.RevokeKey(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrAPIKeyNotFound) {
+				log.Info("can't find active api key with this id", slog.Int("id", id))
+
+				renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - id", invalidParameter)), http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to revoke api key", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("api key revoked", slog.Int("id", id))
+
+		renderResponse(w, r, response.OK(), http.StatusOK)
+		return
+	}
+}
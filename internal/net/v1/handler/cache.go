@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/carservice"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type cacheStatsProvider interface {
+	Stats() carservice.CacheStats
+}
+
+type CacheHandler struct {
+	cacheStatsProvider cacheStatsProvider
+}
+
+func NewCacheHandler(cacheStatsProvider cacheStatsProvider) *CacheHandler {
+	return &CacheHandler{
+		cacheStatsProvider: cacheStatsProvider,
+	}
+}
+
+type GetCacheStatsResponse struct {
+	response.Response
+	Stats carservice.CacheStats `json:"stats"`
+}
+
+// GetStats
+// @Summary Get cache statistics
+// @Tags cache
+// @Description Get hit, miss and eviction counters of the car cache
+// @ID get-cache-stats
+// @Accept json
+// @Produce json
+// @Success 200 {object} GetCacheStatsResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /cache/stats [get]
+func (h *CacheHandler) GetStats(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetCacheStats"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		stats := h.cacheStatsProvider.Stats()
+		log.Debug("cache stats", slog.Any("stats", stats))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, GetCacheStatsResponse{Response: response.OK(), Stats: stats})
+		return
+	}
+}
@@ -6,18 +6,17 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
-	"reflect"
 	"sync"
 
 	"github.com/4aykovski/effective_mobile_test_task/internal/model"
 	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/carinfoservice"
 	"github.com/4aykovski/effective_mobile_test_task/internal/service/carservice"
 	"github.com/4aykovski/effective_mobile_test_task/internal/service/ownerservice"
 	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
 	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
 	"github.com/4aykovski/effective_mobile_test_task/pkg/mapper"
 	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
-	"github.com/4aykovski/effective_mobile_test_task/pkg/tag"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/render"
@@ -29,14 +28,14 @@ const (
 )
 
 type carInfoService interface {
-	GetCarInfoByRegNumber(ctx context.Context, regNumbers []string, errs chan error) map[string]carinfo.CarInfo
+	GetCarInfoByRegNumber(ctx context.Context, regNumbers []string) []carinfoservice.Result
 }
 
 type carService interface {
 	AddNewCars(ctx context.Context, cars []carservice.AddNewCarInput, errs chan error) *sync.Map
 	DeleteCar(ctx context.Context, regNumber string) error
 	UpdateCar(ctx context.Context, car carservice.UpdateCarInput) error
-	GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options) ([]model.Car, error)
+	GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options, sortFields []filter.SortField) ([]model.Car, error)
 }
 
 type ownerService interface {
@@ -47,6 +46,7 @@ type CarHandler struct {
 	carInfoService carInfoService
 	carService     carService
 	ownerService   ownerService
+	filterSchema   *filter.Schema
 }
 
 func NewCarHandler(carInfoService carInfoService, carService carService, ownerService ownerService) *CarHandler {
@@ -54,15 +54,20 @@ func NewCarHandler(carInfoService carInfoService, carService carService, ownerSe
 		carInfoService: carInfoService,
 		carService:     carService,
 		ownerService:   ownerService,
+		filterSchema:   filter.MustSchemaOf(model.Car{}),
 	}
 }
 
 type AddNewCarInput struct {
-	RegNumber []string `json:"regNumber"`
+	RegNumber []string `json:"regNumber" validate:"required"`
+	// BypassCache makes the service ask the car info API even if the answer is cached.
+	BypassCache bool `json:"bypassCache,omitempty"`
 }
 
 type AddNewCarResponse struct {
 	response.Response
+	// ProcessedCars maps registration number to "valid", "invalid" or, if car info wasn't received, the reason,
+	// e.g. "not_found" or "upstream_error".
 	ProcessedCars map[string]string `json:"processed_cars"`
 }
 
@@ -75,8 +80,15 @@ type AddNewCarResponse struct {
 // @Produce json
 // @Param input body AddNewCarInput true "registration numbers"
 // @Success 200 {object} AddNewCarResponse
-// @Failure 400 {object} response.Response
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 429 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 503 {object} AddNewCarResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /cars [post]
 func (h *CarHandler) AddNewCar(log *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -95,31 +107,55 @@ func (h *CarHandler) AddNewCar(log *slog.Logger) http.HandlerFunc {
 		}
 		log.Debug("input", slog.String("input", fmt.Sprint(input)))
 
-		errs := make(chan error, len(input.RegNumber))
-		carInfos := h.carInfoService.GetCarInfoByRegNumber(r.Context(), input.RegNumber, errs)
-		errCount := 0
-		for err := range errs {
-			log.Debug("failed to get car info", slog.String("error", err.Error()))
-			errCount++
+		ctx := r.Context()
+		if input.BypassCache {
+			ctx = carinfo.WithCacheBypass(ctx)
 		}
-		if errCount == len(input.RegNumber) {
-			log.Info("failed to get car info")
 
-			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+		results := h.carInfoService.GetCarInfoByRegNumber(ctx, input.RegNumber)
+		if err := r.Context().Err(); err != nil {
+			log.Info("request cancelled", slog.String("error", err.Error()))
+			return
+		}
+
+		failed := make(map[string]string)
+		upstreamFailures := 0
+		for _, result := range results {
+			if result.Err == nil {
+				continue
+			}
+
+			log.Debug("failed to get car info", slog.String("reg_number", result.RegNumber), slog.String("provider", result.Provider), slog.String("status", result.Status), slog.String("error", result.Err.Error()))
+			failed[result.RegNumber] = result.Status
+
+			switch result.Status {
+			case carinfoservice.StatusUpstreamUnavailable, carinfoservice.StatusUpstreamError, carinfoservice.StatusTimeout:
+				upstreamFailures++
+			}
+		}
+		if upstreamFailures > 0 && upstreamFailures == len(results) {
+			log.Warn("car info api is unavailable")
+
+			render.Status(r, http.StatusServiceUnavailable)
+			render.JSON(w, r, AddNewCarResponse{Response: response.ServiceUnavailable("car info api"), ProcessedCars: failed})
 			return
 		}
 
-		log.Debug("car infos", slog.Any("car_infos", carInfos))
+		log.Debug("car infos", slog.Any("car_infos", results))
 
-		cars, owners := mapper.CarInfoIntoCarAndOwner(carInfos)
+		cars, owners := mapper.CarInfoIntoCarAndOwner(results)
 		log.Debug("cars", slog.Any("cars", cars))
 		log.Debug("owners", slog.Any("owners", owners))
 
-		errs = make(chan error, len(owners))
+		errs := make(chan error, len(owners))
 		h.ownerService.AddNewOwners(r.Context(), owners, errs)
 		for err := range errs {
+			if err == nil {
+				continue
+			}
+
 			log.Debug("failed to add new owner", slog.String("error", err.Error()))
-			if err != nil && !errors.Is(err, repository.ErrOwnerExists) {
+			if !errors.Is(err, repository.ErrOwnerExists) {
 				log.Error("failed to add new owner", slog.String("error", err.Error()))
 
 				renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
@@ -146,6 +182,9 @@ func (h *CarHandler) AddNewCar(log *slog.Logger) http.HandlerFunc {
 			processedCars[regNumber] = status
 			return true
 		})
+		for regNumber, status := range failed {
+			processedCars[regNumber] = status
+		}
 		log.Debug("processed cars", slog.Any("processed_cars", processedCars))
 
 		log.Info("cars processed", slog.Any("cars", processedCars))
@@ -165,8 +204,13 @@ func (h *CarHandler) AddNewCar(log *slog.Logger) http.HandlerFunc {
 // @Produce json
 // @Param regNumber path string true "registration number"
 // @Success 200 {object} response.Response
-// @Failure 400 {object} response.Response
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /cars/{regNumber} [delete]
 func (h *CarHandler) DeleteCar(log *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -219,8 +263,14 @@ type UpdateCarInput struct {
 // @Param regNumber path string true "registration number"
 // @Param input body UpdateCarInput true "car info"
 // @Success 200 {object} response.Response
-// @Failure 400 {object} response.Response
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 429 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /cars/{regNumber} [put]
 func (h *CarHandler) UpdateCar(log *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -283,16 +333,24 @@ type GetCarsResponse struct {
 // @ID get-cars
 // @Accept json
 // @Produce json
-// @Param limit query int false "limit"
-// @Param offset query int false "offset"
+// @Param limit query int false "limit, non-positive means no limit" minimum(-1)
+// @Param offset query int false "offset" minimum(-1)
+// @Param regNumber query string false "car registration number"
 // @Param mark query string false "car mark"
 // @Param ownerName query string false "car owner name"
 // @Param ownerSurname query string false "car owner surname"
 // @Param model query string false "car model"
-// @Param year query int false "car year"
+// @Param year query string false "car year, either a number or operator:number, e.g. gt:2010"
+// @Param filter query string false "boolean filter expression, e.g. (mark eq Lada or mark eq Kia) and year gt 2015"
+// @Param sort query string false "comma separated fields to sort by before pagination, prefix with - for descending order, e.g. -year,mark"
 // @Success 200 {object} GetCarsResponse
-// @Failure 400 {object} response.Response
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /cars [get]
 func (h *CarHandler) GetCars(log *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -302,20 +360,11 @@ func (h *CarHandler) GetCars(log *slog.Logger) http.HandlerFunc {
 			slog.String("request_id", middleware.GetReqID(r.Context())),
 		)
 
-		allowedFilters := make(map[string]string)
-		carType := reflect.TypeOf(model.Car{})
-		for i := 0; i < carType.NumField(); i++ {
-			allowedFilter := tag.ParseJsonTag(carType.Field(i).Tag.Get("json"))
-			type_ := carType.Field(i).Type.Name()
-			allowedFilters[allowedFilter] = type_
-		}
-		log.Debug("allowed filters", slog.Any("filters", allowedFilters))
-
-		filterOptions, err := getFiltersFromUrlQuery(r, allowedFilters)
+		filterOptions, err := getFiltersFromUrlQuery(r, h.filterSchema)
 		if err != nil {
 			log.Info("invalid filter", slog.String("filter", err.Error()))
 
-			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - filter", invalidParameter)), http.StatusBadRequest)
+			renderResponse(w, r, response.BadRequest(invalidFilterMessage(err)), http.StatusBadRequest)
 			return
 		}
 		log.Debug("filter options", slog.Any("filters", filterOptions))
@@ -338,7 +387,16 @@ func (h *CarHandler) GetCars(log *slog.Logger) http.HandlerFunc {
 		}
 		log.Debug("offset", slog.Int("offset", offset))
 
-		cars, err := h.carService.GetCars(r.Context(), limit, offset, filterOptions)
+		sortFields, err := h.filterSchema.ParseSort(r.URL.Query().Get("sort"))
+		if err != nil {
+			log.Info("invalid sort", slog.String("sort", err.Error()))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - sort", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+		log.Debug("sort", slog.Any("sort", sortFields))
+
+		cars, err := h.carService.GetCars(r.Context(), limit, offset, filterOptions, sortFields)
 		if err != nil {
 			if errors.Is(err, repository.ErrCarsNotFound) {
 				log.Info("cars not found")
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/breaker"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type carInfoStatusProvider interface {
+	Status() breaker.Status
+}
+
+type CarInfoHandler struct {
+	carInfoStatusProvider carInfoStatusProvider
+}
+
+func NewCarInfoHandler(carInfoStatusProvider carInfoStatusProvider) *CarInfoHandler {
+	return &CarInfoHandler{
+		carInfoStatusProvider: carInfoStatusProvider,
+	}
+}
+
+type GetCarInfoStatusResponse struct {
+	response.Response
+	Breaker breaker.Status `json:"breaker"`
+}
+
+// GetStatus
+// @Summary Get car info API status
+// @Tags carinfo
+// @Description Get state of the circuit breaker around the car info API
+// @ID get-car-info-status
+// @Accept json
+// @Produce json
+// @Success 200 {object} GetCarInfoStatusResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /carinfo/status [get]
+func (h *CarInfoHandler) GetStatus(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetCarInfoStatus"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		status := h.carInfoStatusProvider.Status()
+		log.Debug("car info api status", slog.Any("status", status))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, GetCarInfoStatusResponse{Response: response.OK(), Breaker: status})
+		return
+	}
+}
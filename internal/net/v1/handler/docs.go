@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"log/slog"
+	"net/http"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	//go:embed docs.html
+	docsPage string
+
+	// swaggerUIFiles are vendored Swagger UI assets, the docs page loads them relative to its url.
+	//go:embed swagger-ui/*.js swagger-ui/*.css swagger-ui/*.png
+	swaggerUIFiles embed.FS
+)
+
+var (
+	docsTemplate = template.Must(template.New("docs").Parse(docsPage))
+	swaggerUI, _ = fs.Sub(swaggerUIFiles, "swagger-ui")
+)
+
+type apiSpec interface {
+	ReadDoc() string
+}
+
+type DocsHandler struct {
+	spec    apiSpec
+	specURL string
+}
+
+// NewDocsHandler returns handler of spec, the docs page loads the spec from specURL.
+func NewDocsHandler(spec apiSpec, specURL string) *DocsHandler {
+	return &DocsHandler{
+		spec:    spec,
+		specURL: specURL,
+	}
+}
+
+func (h *DocsHandler) GetSpecJSON(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(h.spec.ReadDoc()))
+	}
+}
+
+func (h *DocsHandler) GetSpecYAML(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetSpecYAML"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		spec, err := jsonToYAML([]byte(h.spec.ReadDoc()))
+		if err != nil {
+			log.Error("failed to convert spec to yaml", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(spec)
+	}
+}
+
+func (h *DocsHandler) GetUI(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetUI"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var page bytes.Buffer
+		if err := docsTemplate.Execute(&page, struct{ SpecURL string }{h.specURL}); err != nil {
+			log.Error("failed to render docs page", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(page.Bytes())
+	}
+}
+
+// GetAsset serves Swagger UI file named by the wildcard of the route.
+func (h *DocsHandler) GetAsset(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := chi.URLParam(r, "*")
+		if info, err := fs.Stat(swaggerUI, name); err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeFileFS(w, r, swaggerUI, name)
+	}
+}
+
+// jsonToYAML converts JSON document to block style YAML keeping order of keys.
+func jsonToYAML(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	resetStyle(&doc)
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func resetStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style = 0
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		node.Style = 0
+	}
+
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
@@ -1,10 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
 	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
@@ -42,42 +43,44 @@ func getOffsetFromUrlQuery(r *http.Request) (int, error) {
 	return offset, nil
 }
 
-func getFiltersFromUrlQuery(r *http.Request, allowedFilters map[string]string) (filter.Options, error) {
-	filterOptions := filter.NewOptions()
-	for filterName, filterType := range allowedFilters {
-		strValue := r.URL.Query().Get(filterName)
-		if strValue != "" {
-			var type_ string
-			var operator string
-
-			switch filterType {
-			case filter.DataTypeInt:
-				type_ = filter.DataTypeInt
-				operator = filter.OperatorEq
+func getFiltersFromUrlQuery(r *http.Request, schema *filter.Schema) (filter.Options, error) {
+	return getFiltersFromQuery(r.URL.Query(), schema)
+}
 
-				if strings.Index(strValue, ":") != -1 {
-					split := strings.Split(strValue, ":")
-					operator = split[0]
-					strValue = split[1]
+func getFiltersFromQuery(query url.Values, schema *filter.Schema) (filter.Options, error) {
+	filterOptions, err := schema.OptionsFromQuery(query)
+	if err != nil {
+		return nil, err
+	}
 
-					if _, err := strconv.Atoi(strValue); err != nil {
-						return nil, fmt.Errorf("failed to parse filter: %w", err)
-					}
-					type_ = filter.DataTypeInt
-				} else {
-					if _, err := strconv.Atoi(strValue); err != nil {
-						return nil, fmt.Errorf("failed to parse filter: %w", err)
-					}
-				}
-			case filter.DataTypeStr:
-				type_ = filter.DataTypeInt
-				operator = filter.OperatorEq
-			}
+	if strExpr := query.Get("filter"); strExpr != "" {
+		expr, err := filter.ParseExpression(strExpr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse filter expression: %w", err)
+		}
 
-			if err := filterOptions.AddField(filterName, operator, strValue, type_); err != nil {
-				return nil, fmt.Errorf("failed to parse filter: %w", err)
-			}
+		if err = schema.ValidateExpression(expr); err != nil {
+			return nil, fmt.Errorf("failed to validate filter expression: %w", err)
 		}
+
+		filterOptions.SetExpression(expr)
 	}
+
 	return filterOptions, nil
 }
+
+// invalidFilterMessage returns bad request message for filter parsing error with details useful for the client.
+func invalidFilterMessage(err error) string {
+	msg := fmt.Sprintf("%s - filter", invalidParameter)
+
+	var exprErr *filter.ExpressionError
+	if errors.As(err, &exprErr) {
+		return fmt.Sprintf("%s: %s", msg, exprErr.Error())
+	}
+
+	if errors.Is(err, filter.ErrUnknownField) || errors.Is(err, filter.ErrUnsupportedOperator) || errors.Is(err, filter.ErrInvalidValue) {
+		return fmt.Sprintf("%s: %s", msg, errors.Unwrap(err).Error())
+	}
+
+	return msg
+}
@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/searchservice"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type searchService interface {
+	AddSearch(ctx context.Context, input searchservice.AddSearchInput) (model.SavedSearch, error)
+	GetSearch(ctx context.Context, id int) (model.SavedSearch, error)
+	GetSearches(ctx context.Context) ([]model.SavedSearch, error)
+}
+
+type SearchHandler struct {
+	searchService searchService
+	carService    carService
+	filterSchema  *filter.Schema
+}
+
+func NewSearchHandler(searchService searchService, carService carService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+		carService:    carService,
+		filterSchema:  filter.MustSchemaOf(model.Car{}),
+	}
+}
+
+type AddSearchInput struct {
+	Name   string `json:"name" validate:"required" minLength:"1"`
+	Filter string `json:"filter,omitempty"`
+	// Fields map filter fields to their values, e.g. {"year": ["gt:2010", "lt:2020"]}
+	Fields map[string][]string `json:"fields,omitempty"`
+	// Sort is comma separated fields prefixed with - for descending order, e.g. -year,mark
+	Sort   string `json:"sort,omitempty"`
+	Limit  int    `json:"limit,omitempty" minimum:"0"`
+	Offset int    `json:"offset,omitempty" minimum:"0"`
+}
+
+type SearchResponse struct {
+	response.Response
+	Search model.SavedSearch `json:"search"`
+}
+
+type GetSearchesResponse struct {
+	response.Response
+	Searches []model.SavedSearch `json:"searches"`
+}
+
+// AddSearch
+// @Summary Save search
+// @Tags searches
+// @Description Save GET /cars filter combination under a name
+// @ID add-search
+// @Accept json
+// @Produce json
+// @Param input body AddSearchInput true "search parameters"
+// @Success 200 {object} SearchResponse
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /searches [post]
+func (h *SearchHandler) AddSearch(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "AddSearch"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var input AddSearchInput
+		if err := render.DecodeJSON(r.Body, &input); err != nil {
+			log.Info("request with wrong body")
+
+			renderResponse(w, r, response.BadRequest(requestWithWrongBody), http.StatusBadRequest)
+			return
+		}
+		log.Debug("input", slog.Any("input", input))
+
+		if input.Name == "" {
+			log.Info("empty search name")
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - name", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		if input.Limit < 0 {
+			log.Info("invalid limit", slog.Int("limit", input.Limit))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - limit", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		if input.Offset < 0 {
+			log.Info("invalid offset", slog.Int("offset", input.Offset))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - offset", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := h.searchFilters(input.Filter, input.Fields); err != nil {
+			log.Info("invalid filter", slog.String("filter", err.Error()))
+
+			renderResponse(w, r, response.BadRequest(invalidFilterMessage(err)), http.StatusBadRequest)
+			return
+		}
+
+		if _, err := h.filterSchema.ParseSort(input.Sort); err != nil {
+			log.Info("invalid sort", slog.String("sort", err.Error()))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - sort", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		search, err := h.searchService.AddSearch(r.Context(), searchservice.AddSearchInput{
+			Name:   input.Name,
+			Filter: input.Filter,
+			Fields: input.Fields,
+			Sort:   input.Sort,
+			Limit:  input.Limit,
+			Offset: input.Offset,
+		})
+		if err != nil {
+			if errors.Is(err, repository.ErrSavedSearchExists) {
+				log.Info("saved search with this name already exists", slog.String("name", input.Name))
+
+				renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - name", invalidParameter)), http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to add saved search", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("saved search added", slog.Int("id", search.ID), slog.String("name", search.Name))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, SearchResponse{Response: response.OK(), Search: search})
+		return
+	}
+}
+
+// GetSearches
+// @Summary Get saved searches
+// @Tags searches
+// @Description Get all saved searches
+// @ID get-searches
+// @Accept json
+// @Produce json
+// @Success 200 {object} GetSearchesResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /searches [get]
+func (h *SearchHandler) GetSearches(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetSearches"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		searches, err := h.searchService.GetSearches(r.Context())
+		if err != nil {
+			log.Error("failed to get saved searches", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("saved searches found", slog.Int("searches_count", len(searches)))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, GetSearchesResponse{Response: response.OK(), Searches: searches})
+		return
+	}
+}
+
+// GetSearchResults
+// @Summary Get saved search results
+// @Tags searches
+// @Description Get cars matching saved search
+// @ID get-search-results
+// @Accept json
+// @Produce json
+// @Param id path int true "saved search id"
+// @Success 200 {object} GetCarsResponse
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /searches/{id}/results [get]
+func (h *SearchHandler) GetSearchResults(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetSearchResults"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Info("invalid search id", slog.String("id", chi.URLParam(r, "id")))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - id", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+		log.Debug("search id", slog.Int("id", id))
+
+		search, err := h.searchService.GetSearch(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrSavedSearchNotFound) {
+				log.Info("can't find saved search with this id", slog.Int("id", id))
+
+				renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - id", invalidParameter)), http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to get saved search", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+		log.Debug("saved search", slog.Any("search", search))
+
+		filterOptions, err := h.searchFilters(search.Filter, search.Fields)
+		if err != nil {
+			log.Error("stored search has invalid filter", slog.Int("id", id), slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		sortFields, err := h.filterSchema.ParseSort(search.Sort)
+		if err != nil {
+			log.Error("stored search has invalid sort", slog.Int("id", id), slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		limit := search.Limit
+		if limit == 0 {
+			limit = -1
+		}
+
+		cars, err := h.carService.GetCars(r.Context(), limit, search.Offset, filterOptions, sortFields)
+		if err != nil {
+			if errors.Is(err, repository.ErrCarsNotFound) {
+				log.Info("cars not found")
+
+				render.Status(r, http.StatusOK)
+				render.JSON(w, r, GetCarsResponse{
+					Cars:     nil,
+					Response: response.OK(),
+				})
+				return
+			}
+
+			log.Error("failed to get cars", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("cars found", slog.Int("id", id), slog.Int("cars_count", len(cars)))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, GetCarsResponse{
+			Cars:     cars,
+			Response: response.OK(),
+		})
+		return
+	}
+}
+
+// searchFilters builds filter options from saved search parameters the same way GetCars does from url query.
+func (h *SearchHandler) searchFilters(expr string, fields map[string][]string) (filter.Options, error) {
+	query := url.Values{}
+	for name, values := range fields {
+		if _, ok := h.filterSchema.Field(name); !ok {
+			return nil, fmt.Errorf("failed to parse filter: %w", fmt.Errorf("%w %q", filter.ErrUnknownField, name))
+		}
+		query[name] = values
+	}
+
+	if expr != "" {
+		query.Set("filter", expr)
+	}
+
+	return getFiltersFromQuery(query, h.filterSchema)
+}
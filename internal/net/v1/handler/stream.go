@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/streamservice"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	lastEventIDHeader = "Last-Event-ID"
+
+	defaultHeartbeatInterval = 15 * time.Second
+)
+
+type streamService interface {
+	Subscribe(lastEventID uint64) (replay []streamservice.StreamEvent, events <-chan streamservice.StreamEvent, unsubscribe func())
+}
+
+type StreamHandler struct {
+	streamService     streamService
+	filterSchema      *filter.Schema
+	heartbeatInterval time.Duration
+}
+
+// NewStreamHandler returns handler sending heartbeats to idle subscribers every heartbeatInterval, non-positive
+// interval means the default of 15 seconds.
+func NewStreamHandler(streamService streamService, heartbeatInterval time.Duration) *StreamHandler {
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = defaultHeartbeatInterval
+	}
+
+	return &StreamHandler{
+		streamService:     streamService,
+		filterSchema:      filter.MustSchemaOf(model.Car{}),
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// GetCarEvents
+// @Summary Stream car events
+// @Tags cars
+// @Description Server-Sent Events stream of created, updated and deleted cars.
+// @Description Events can be filtered with the same parameters as GET /cars, missed events are resent after Last-Event-ID.
+// @ID get-car-events
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "id of the last received event" minimum(0)
+// @Param lastEventId query int false "id of the last received event, for clients that can't set headers" minimum(0)
+// @Param mark query string false "car mark"
+// @Param model query string false "car model"
+// @Param year query string false "car year, either a number or operator:number, e.g. gt:2010"
+// @Param filter query string false "boolean filter expression, e.g. (mark eq Lada or mark eq Kia) and year gt 2015"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /cars/events [get]
+func (h *StreamHandler) GetCarEvents(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetCarEvents"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		filterOptions, err := getFiltersFromUrlQuery(r, h.filterSchema)
+		if err != nil {
+			log.Info("invalid filter", slog.String("filter", err.Error()))
+
+			renderResponse(w, r, response.BadRequest(invalidFilterMessage(err)), http.StatusBadRequest)
+			return
+		}
+
+		lastEventID, err := getLastEventID(r)
+		if err != nil {
+			log.Info("invalid last event id", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - %s", invalidParameter, lastEventIDHeader)), http.StatusBadRequest)
+			return
+		}
+		log.Debug("last event id", slog.Uint64("last_event_id", lastEventID))
+
+		rc := http.NewResponseController(w)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		if err = rc.Flush(); err != nil {
+			log.Error("streaming is not supported", slog.String("error", err.Error()))
+			return
+		}
+
+		replay, events, unsubscribe := h.streamService.Subscribe(lastEventID)
+		defer unsubscribe()
+
+		log.Info("client subscribed", slog.Int("replayed", len(replay)))
+
+		send := func(event streamservice.StreamEvent) error {
+			ok, err := h.filterSchema.Match(filterOptions, *event.Event.Car)
+			if err != nil || !ok {
+				return err
+			}
+
+			data, err := json.Marshal(event.Event)
+			if err != nil {
+				return err
+			}
+
+			if _, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Event.Type, data); err != nil {
+				return err
+			}
+
+			return rc.Flush()
+		}
+
+		for _, event := range replay {
+			if err = send(event); err != nil {
+				log.Info("failed to send event", slog.String("error", err.Error()))
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(h.heartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				log.Info("client disconnected")
+				return
+			case event, ok := <-events:
+				if !ok {
+					log.Info("client is too slow, closing stream")
+					return
+				}
+
+				if err = send(event); err != nil {
+					log.Info("failed to send event", slog.String("error", err.Error()))
+					return
+				}
+			case <-heartbeat.C:
+				if _, err = fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					log.Info("failed to send heartbeat", slog.String("error", err.Error()))
+					return
+				}
+
+				if err = rc.Flush(); err != nil {
+					log.Info("failed to send heartbeat", slog.String("error", err.Error()))
+					return
+				}
+			}
+		}
+	}
+}
+
+func getLastEventID(r *http.Request) (uint64, error) {
+	strID := r.Header.Get(lastEventIDHeader)
+	if strID == "" {
+		strID = r.URL.Query().Get("lastEventId")
+	}
+
+	if strID == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(strID, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse last event id: %w", err)
+	}
+
+	return id, nil
+}
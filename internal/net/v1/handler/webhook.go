@@ -0,0 +1,281 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/webhookservice"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type webhookService interface {
+	AddWebhook(ctx context.Context, input webhookservice.AddWebhookInput) (model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetDeliveries(ctx context.Context, webhookID int) ([]model.WebhookDelivery, error)
+}
+
+type WebhookHandler struct {
+	webhookService webhookService
+}
+
+func NewWebhookHandler(webhookService webhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+type AddWebhookInput struct {
+	URL    string   `json:"url" validate:"required" minLength:"1"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty" enums:"car.created,car.updated,car.deleted,owner.created"`
+}
+
+type WebhookResponse struct {
+	response.Response
+	Webhook model.Webhook `json:"webhook"`
+}
+
+type GetWebhooksResponse struct {
+	response.Response
+	Webhooks []model.Webhook `json:"webhooks"`
+}
+
+type GetDeliveriesResponse struct {
+	response.Response
+	Deliveries []model.WebhookDelivery `json:"deliveries"`
+}
+
+// AddWebhook
+// @Summary Add webhook
+// @Tags webhooks
+// @Description Subscribe url to car events. Without events the webhook receives all of them.
+// @Description Every delivery is signed with HMAC-SHA256 of the body in X-Webhook-Signature header.
+// @ID add-webhook
+// @Accept json
+// @Produce json
+// @Param input body AddWebhookInput true "webhook"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (h *WebhookHandler) AddWebhook(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "AddWebhook"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var input AddWebhookInput
+		if err := render.DecodeJSON(r.Body, &input); err != nil {
+			log.Info("request with wrong body")
+
+			renderResponse(w, r, response.BadRequest(requestWithWrongBody), http.StatusBadRequest)
+			return
+		}
+		log.Debug("input", slog.String("url", input.URL), slog.Any("events", input.Events))
+
+		u, err := url.Parse(input.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			log.Info("invalid webhook url", slog.String("url", input.URL))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - url", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		for _, event := range input.Events {
+			if !slices.Contains(model.EventTypes, event) {
+				log.Info("unknown event type", slog.String("event", event))
+
+				renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - events", invalidParameter)), http.StatusBadRequest)
+				return
+			}
+		}
+
+		webhook, err := h.webhookService.AddWebhook(r.Context(), webhookservice.AddWebhookInput{
+			URL:    input.URL,
+			Secret: input.Secret,
+			Events: input.Events,
+		})
+		if err != nil {
+			log.Error("failed to add webhook", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("webhook added", slog.Int("id", webhook.ID))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, WebhookResponse{Response: response.OK(), Webhook: webhook})
+		return
+	}
+}
+
+// GetWebhooks
+// @Summary Get webhooks
+// @Tags webhooks
+// @Description Get all webhook subscriptions
+// @ID get-webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} GetWebhooksResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetWebhooks(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetWebhooks"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		webhooks, err := h.webhookService.GetWebhooks(r.Context())
+		if err != nil {
+			log.Error("failed to get webhooks", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("webhooks found", slog.Int("webhooks_count", len(webhooks)))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, GetWebhooksResponse{Response: response.OK(), Webhooks: webhooks})
+		return
+	}
+}
+
+// DeleteWebhook
+// @Summary Delete webhook
+// @Tags webhooks
+// @Description Delete webhook subscription with its delivery log
+// @ID delete-webhook
+// @Accept json
+// @Produce json
+// @Param id path int true "webhook id"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "DeleteWebhook"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Info("invalid webhook id", slog.String("id", chi.URLParam(r, "id")))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - id", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		err = h.webhookService.DeleteWebhook(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrWebhookNotFound) {
+				log.Info("can't find webhook with this id", slog.Int("id", id))
+
+				renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - id", invalidParameter)), http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to delete webhook", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("webhook deleted", slog.Int("id", id))
+
+		renderResponse(w, r, response.OK(), http.StatusOK)
+		return
+	}
+}
+
+// GetDeliveries
+// @Summary Get webhook deliveries
+// @Tags webhooks
+// @Description Get delivery log of webhook, newest first
+// @ID get-webhook-deliveries
+// @Accept json
+// @Produce json
+// @Param id path int true "webhook id"
+// @Success 200 {object} GetDeliveriesResponse
+// @Failure 400 {object} response.ValidationErrorResponse
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(log *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		log = log.With(
+			slog.String("handler", "GetDeliveries"),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			log.Info("invalid webhook id", slog.String("id", chi.URLParam(r, "id")))
+
+			renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - id", invalidParameter)), http.StatusBadRequest)
+			return
+		}
+
+		deliveries, err := h.webhookService.GetDeliveries(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, repository.ErrWebhookNotFound) {
+				log.Info("can't find webhook with this id", slog.Int("id", id))
+
+				renderResponse(w, r, response.BadRequest(fmt.Sprintf("%s - id", invalidParameter)), http.StatusBadRequest)
+				return
+			}
+
+			log.Error("failed to get deliveries", slog.String("error", err.Error()))
+
+			renderResponse(w, r, response.InternalError(), http.StatusInternalServerError)
+			return
+		}
+
+		log.Info("deliveries found", slog.Int("id", id), slog.Int("deliveries_count", len(deliveries)))
+
+		render.Status(r, http.StatusOK)
+		render.JSON(w, r, GetDeliveriesResponse{Response: response.OK(), Deliveries: deliveries})
+		return
+	}
+}
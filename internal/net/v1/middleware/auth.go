@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/apikeyservice"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/jwt"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+type apiKeyAuthenticator interface {
+	Authenticate(ctx context.Context, value string) (model.APIKey, error)
+}
+
+type tokenVerifier interface {
+	Verify(token string) (jwt.Claims, error)
+}
+
+type identityContextKey struct{}
+
+// IdentityFromContext returns client the request was authenticated as.
+func IdentityFromContext(ctx context.Context) (model.Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(model.Identity)
+	return identity, ok
+}
+
+// Auth rejects requests without valid credentials. API key is taken from X-API-Key header, Authorization bearer
+// token is verified as JWT if it looks like one and as API key otherwise. Nil authenticator or verifier
+// disables the corresponding kind of credentials.
+func Auth(log *slog.Logger, authenticator apiKeyAuthenticator, verifier tokenVerifier) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		log = log.With(
+			slog.String("middleware", "auth"),
+		)
+
+		log.Debug("Auth middleware initialized")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			log := log.With(
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+			)
+
+			var (
+				identity model.Identity
+				err      error
+			)
+			key, token := credentialsFromRequest(r)
+			switch {
+			case token != "" && verifier != nil:
+				identity, err = verifyToken(verifier, token)
+			case key != "" && authenticator != nil:
+				identity, err = authenticateKey(r.Context(), authenticator, key)
+			default:
+				log.Info("request without credentials")
+
+				renderError(w, r, response.Unauthorized("credentials are required"), http.StatusUnauthorized)
+				return
+			}
+			if err != nil {
+				if errors.Is(err, apikeyservice.ErrInvalidKey) || errors.Is(err, jwt.ErrInvalidToken) {
+					log.Info("request with invalid credentials", slog.String("error", err.Error()))
+
+					renderError(w, r, response.Unauthorized("invalid credentials"), http.StatusUnauthorized)
+					return
+				}
+
+				log.Error("failed to authenticate request", slog.String("error", err.Error()))
+
+				renderError(w, r, response.InternalError(), http.StatusInternalServerError)
+				return
+			}
+
+			handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), identityContextKey{}, identity)))
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RequireScope rejects requests of clients which aren't granted every one of scopes. It must be used after Auth.
+func RequireScope(log *slog.Logger, scopes ...string) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			identity, _ := IdentityFromContext(r.Context())
+			for _, scope := range scopes {
+				if !identity.HasScope(scope) {
+					log.Info("request without required scope",
+						slog.String("middleware", "require_scope"),
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+						slog.String("subject", identity.Subject),
+						slog.String("scope", scope),
+					)
+
+					renderError(w, r, response.Forbidden("missing scope "+scope), http.StatusForbidden)
+					return
+				}
+			}
+
+			handler.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// credentialsFromRequest returns API key or bearer token which looks like JWT.
+func credentialsFromRequest(r *http.Request) (key, token string) {
+	if key = r.Header.Get(APIKeyHeader); key != "" {
+		return key, ""
+	}
+
+	scheme, value, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", ""
+	}
+
+	value = strings.TrimSpace(value)
+	if strings.Count(value, ".") == 2 {
+		return "", value
+	}
+
+	return value, ""
+}
+
+func authenticateKey(ctx context.Context, authenticator apiKeyAuthenticator, value string) (model.Identity, error) {
+	key, err := authenticator.Authenticate(ctx, value)
+	if err != nil {
+		return model.Identity{}, err
+	}
+
+	return key.Identity(), nil
+}
+
+func verifyToken(verifier tokenVerifier, token string) (model.Identity, error) {
+	claims, err := verifier.Verify(token)
+	if err != nil {
+		return model.Identity{}, err
+	}
+
+	return model.Identity{
+		Subject: "jwt:" + claims.Subject,
+		Scopes:  claims.AllScopes(),
+	}, nil
+}
+
+func renderError(w http.ResponseWriter, r *http.Request, resp response.Response, statusCode int) {
+	if statusCode == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+
+	render.Status(r, statusCode)
+	render.JSON(w, r, resp)
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/config"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// CORS allows browsers to call the API from origins listed in cfg. Preflight requests are answered here and
+// don't reach the handlers, so they need no credentials.
+func CORS(log *slog.Logger, cfg config.CORSConfig) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		log = log.With(
+			slog.String("middleware", "cors"),
+		)
+
+		log.Debug("CORS middleware initialized", slog.Any("origins", cfg.AllowedOrigins))
+
+		var (
+			anyOrigin      = slices.Contains(cfg.AllowedOrigins, "*")
+			anyHeader      = slices.Contains(cfg.AllowedHeaders, "*")
+			allowedMethods = strings.Join(cfg.AllowedMethods, ", ")
+			allowedHeaders = strings.Join(cfg.AllowedHeaders, ", ")
+			exposedHeaders = strings.Join(cfg.ExposedHeaders, ", ")
+			maxAge         = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+		)
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			w.Header().Add("Vary", "Origin")
+			if preflight {
+				w.Header().Add("Vary", "Access-Control-Request-Method")
+				w.Header().Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			if origin == "" {
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			log := log.With(
+				slog.String("request_id", middleware.GetReqID(r.Context())),
+				slog.String("origin", origin),
+			)
+
+			if !anyOrigin && !slices.ContainsFunc(cfg.AllowedOrigins, func(pattern string) bool { return matchOrigin(pattern, origin) }) {
+				if preflight {
+					log.Info("preflight request from not allowed origin")
+
+					renderError(w, r, response.Forbidden("origin is not allowed"), http.StatusForbidden)
+					return
+				}
+
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			// config doesn't allow credentials with any origin
+			if anyOrigin {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposedHeaders != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+				}
+
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			method := r.Header.Get("Access-Control-Request-Method")
+			if !slices.Contains(cfg.AllowedMethods, method) {
+				log.Info("preflight request for not allowed method", slog.String("method", method))
+
+				renderError(w, r, response.Forbidden("method is not allowed"), http.StatusForbidden)
+				return
+			}
+
+			requested := r.Header.Get("Access-Control-Request-Headers")
+			if !anyHeader {
+				for _, header := range strings.Split(requested, ",") {
+					header = strings.TrimSpace(header)
+					if header != "" && !slices.ContainsFunc(cfg.AllowedHeaders, func(allowed string) bool { return strings.EqualFold(allowed, header) }) {
+						log.Info("preflight request for not allowed header", slog.String("header", header))
+
+						renderError(w, r, response.Forbidden("header "+header+" is not allowed"), http.StatusForbidden)
+						return
+					}
+				}
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+			if anyHeader && requested != "" {
+				w.Header().Set("Access-Control-Allow-Headers", requested)
+			} else if allowedHeaders != "" {
+				w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+			}
+			if cfg.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// matchOrigin reports whether origin matches pattern, which is either exact origin or contains a single wildcard,
+// e.g. https://*.example.com.
+func matchOrigin(pattern, origin string) bool {
+	prefix, suffix, ok := strings.Cut(pattern, "*")
+	if !ok {
+		return strings.EqualFold(pattern, origin)
+	}
+
+	origin = strings.ToLower(origin)
+	prefix, suffix = strings.ToLower(prefix), strings.ToLower(suffix)
+
+	return len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
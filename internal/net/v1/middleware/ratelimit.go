@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/ratelimit"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// RateLimit limits requests of every client, identified by its credentials or by IP address for anonymous
+// requests. Safe methods are limited by read limiter and the others by write limiter, nil limiter doesn't limit.
+// It must be used after Auth to tell clients by credentials.
+func RateLimit(log *slog.Logger, read, write *ratelimit.Limiter) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		log = log.With(
+			slog.String("middleware", "rate_limit"),
+		)
+
+		log.Debug("Rate limit middleware initialized")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			limiter := write
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				limiter = read
+			}
+
+			if limiter != nil && !allow(log, w, r, limiter, clientKey(r)) {
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// RateLimitByIP limits all requests of every IP address, nil limiter doesn't limit. It must be used before Auth,
+// so requests with missing or invalid credentials are limited too.
+func RateLimitByIP(log *slog.Logger, limiter *ratelimit.Limiter) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		log = log.With(
+			slog.String("middleware", "rate_limit_by_ip"),
+		)
+
+		log.Debug("Rate limit by ip middleware initialized")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			if limiter != nil && !allow(log, w, r, limiter, ipKey(r)) {
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+// allow takes a token of client from limiter and sets rate limit headers. If the limit is exceeded, it responds
+// with 429 and returns false.
+func allow(log *slog.Logger, w http.ResponseWriter, r *http.Request, limiter *ratelimit.Limiter, client string) bool {
+	res := limiter.Allow(client)
+
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+	w.Header().Set("RateLimit-Policy", strconv.Itoa(res.Limit)+";w="+strconv.Itoa(seconds(limiter.Window())))
+
+	if !res.Allowed {
+		log.Info("rate limit exceeded",
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+			slog.String("client", client),
+		)
+
+		w.Header().Set("Retry-After", strconv.Itoa(seconds(res.RetryAfter)))
+		renderError(w, r, response.TooManyRequests(), http.StatusTooManyRequests)
+		return false
+	}
+
+	return true
+}
+
+func clientKey(r *http.Request) string {
+	if identity, ok := IdentityFromContext(r.Context()); ok {
+		return identity.Subject
+	}
+
+	return ipKey(r)
+}
+
+func ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// seconds rounds d up to whole seconds as rate limit headers expect.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
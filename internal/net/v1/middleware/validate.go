@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/openapi"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+// Validate rejects requests which don't match the API spec with 400 listing every violation. It must be used in
+// the router mounted at base path of the spec, paths are matched relative to it. Bodies over the limit of
+// http.MaxBytesReader, e.g. set by chi RequestSize middleware, are rejected with 413.
+func Validate(log *slog.Logger, validator *openapi.Validator) func(handler http.Handler) http.Handler {
+	return func(handler http.Handler) http.Handler {
+		log = log.With(
+			slog.String("middleware", "validate"),
+		)
+
+		log.Debug("Validate middleware initialized")
+
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			path := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePath != "" {
+				path = rctx.RoutePath
+			}
+
+			violations, err := validator.Validate(r, path)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				switch {
+				case errors.As(err, &tooLarge):
+					log.Info("request body too large",
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+						slog.Int64("limit", tooLarge.Limit),
+					)
+
+					renderError(w, r, response.RequestTooLarge(), http.StatusRequestEntityTooLarge)
+				case errors.Is(err, openapi.ErrUnreadableBody):
+					log.Info("failed to read request body",
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+						slog.String("error", err.Error()),
+					)
+
+					renderError(w, r, response.BadRequest("can't read body"), http.StatusBadRequest)
+				default:
+					log.Error("failed to validate request",
+						slog.String("request_id", middleware.GetReqID(r.Context())),
+						slog.String("error", err.Error()),
+					)
+
+					renderError(w, r, response.InternalError(), http.StatusInternalServerError)
+				}
+				return
+			}
+
+			if len(violations) > 0 {
+				log.Info("request doesn't match api spec",
+					slog.String("request_id", middleware.GetReqID(r.Context())),
+					slog.Any("violations", violations),
+				)
+
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.ValidationError(responseViolations(violations)))
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		}
+
+		return http.HandlerFunc(fn)
+	}
+}
+
+func responseViolations(violations []openapi.Violation) []response.Violation {
+	res := make([]response.Violation, 0, len(violations))
+	for _, violation := range violations {
+		res = append(res, response.Violation{
+			In:      violation.In,
+			Name:    violation.Name,
+			Message: violation.Message,
+		})
+	}
+
+	return res
+}
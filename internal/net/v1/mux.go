@@ -3,24 +3,37 @@ package v1
 import (
 	"context"
 	"log/slog"
+	"net/http"
+	"path"
 	"sync"
+	"time"
 
+	"github.com/4aykovski/effective_mobile_test_task/internal/config"
 	"github.com/4aykovski/effective_mobile_test_task/internal/model"
 	"github.com/4aykovski/effective_mobile_test_task/internal/net/v1/handler"
 	"github.com/4aykovski/effective_mobile_test_task/internal/net/v1/middleware"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/apikeyservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/carinfoservice"
 	"github.com/4aykovski/effective_mobile_test_task/internal/service/carservice"
 	"github.com/4aykovski/effective_mobile_test_task/internal/service/ownerservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/searchservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/streamservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/webhookservice"
 	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
-	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/openapi"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/breaker"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/jwt"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/ratelimit"
 	"github.com/go-chi/chi/v5"
 	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/swaggo/swag"
 )
 
 type carService interface {
 	AddNewCars(ctx context.Context, cars []carservice.AddNewCarInput, errs chan error) *sync.Map
 	DeleteCar(ctx context.Context, regNumber string) error
 	UpdateCar(ctx context.Context, car carservice.UpdateCarInput) error
-	GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options) ([]model.Car, error)
+	GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options, sortFields []filter.SortField) ([]model.Car, error)
 }
 
 type ownerService interface {
@@ -28,30 +41,174 @@ type ownerService interface {
 }
 
 type carInfoService interface {
-	GetCarInfoByRegNumber(ctx context.Context, regNumbers []string, errs chan error) map[string]carinfo.CarInfo
+	GetCarInfoByRegNumber(ctx context.Context, regNumbers []string) []carinfoservice.Result
 }
 
-func NewMux(
-	log *slog.Logger,
-	carService carService,
-	ownerService ownerService,
-	carInfoService carInfoService,
-) *chi.Mux {
+type searchService interface {
+	AddSearch(ctx context.Context, input searchservice.AddSearchInput) (model.SavedSearch, error)
+	GetSearch(ctx context.Context, id int) (model.SavedSearch, error)
+	GetSearches(ctx context.Context) ([]model.SavedSearch, error)
+}
+
+type webhookService interface {
+	AddWebhook(ctx context.Context, input webhookservice.AddWebhookInput) (model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetDeliveries(ctx context.Context, webhookID int) ([]model.WebhookDelivery, error)
+}
+
+type cacheStatsProvider interface {
+	Stats() carservice.CacheStats
+}
+
+type carInfoStatusProvider interface {
+	Status() breaker.Status
+}
+
+type apiKeyService interface {
+	CreateKey(ctx context.Context, input apikeyservice.CreateKeyInput) (model.APIKey, string, error)
+	GetKeys(ctx context.Context) ([]model.APIKey, error)
+	RevokeKey(ctx context.Context, id int) error
+	Authenticate(ctx context.Context, value string) (model.APIKey, error)
+}
+
+type tokenVerifier interface {
+	Verify(token string) (jwt.Claims, error)
+}
+
+type streamService interface {
+	Subscribe(lastEventID uint64) (replay []streamservice.StreamEvent, events <-chan streamservice.StreamEvent, unsubscribe func())
+}
+
+// RateLimits configures limiting of requests per client, nil limiter leaves its routes unlimited.
+type RateLimits struct {
+	// IP limits all requests of every IP address before authentication, so guessing credentials is limited too.
+	IP *ratelimit.Limiter
+	// Read limits GET requests, Write limits the others.
+	Read  *ratelimit.Limiter
+	Write *ratelimit.Limiter
+	// TrustProxy takes client IP from X-Forwarded-For and X-Real-IP headers set by a reverse proxy.
+	TrustProxy bool
+}
+
+// Deps holds everything the API is built from. Services not needed by a caller can be left nil, requests to
+// their routes then fail.
+type Deps struct {
+	CarService            carService
+	OwnerService          ownerService
+	CarInfoService        carInfoService
+	SearchService         searchService
+	WebhookService        webhookService
+	StreamService         streamService
+	CacheStatsProvider    cacheStatsProvider
+	CarInfoStatusProvider carInfoStatusProvider
+
+	// StreamHeartbeatInterval is period of comments sent to idle event stream subscribers, zero means the default.
+	StreamHeartbeatInterval time.Duration
+
+	// APIKeyService and TokenVerifier authenticate requests, then every route requires its scopes. If both are nil
+	// the API is open.
+	APIKeyService apiKeyService
+	TokenVerifier tokenVerifier
+
+	RateLimits RateLimits
+	CORS       config.CORSConfig
+
+	// APISpec is served at /api/v1/openapi.json and /api/v1/openapi.yaml with Swagger UI at /docs, all of them
+	// without authentication. Nil disables them.
+	APISpec *swag.Spec
+	// RequestValidator rejects requests violating the spec with 400, nil disables validation.
+	RequestValidator *openapi.Validator
+	// MaxBodySize limits size of request bodies, larger ones are rejected with 413. Zero means no limit.
+	MaxBodySize int64
+}
+
+// NewMux returns router of the API.
+func NewMux(log *slog.Logger, deps Deps) *chi.Mux {
 	var (
-		carHandler = handler.NewCarHandler(carInfoService, carService, ownerService)
-		mux        = chi.NewMux()
+		carHandler     = handler.NewCarHandler(deps.CarInfoService, deps.CarService, deps.OwnerService)
+		searchHandler  = handler.NewSearchHandler(deps.SearchService, deps.CarService)
+		webhookHandler = handler.NewWebhookHandler(deps.WebhookService)
+		streamHandler  = handler.NewStreamHandler(deps.StreamService, deps.StreamHeartbeatInterval)
+		cacheHandler   = handler.NewCacheHandler(deps.CacheStatsProvider)
+		carInfoHandler = handler.NewCarInfoHandler(deps.CarInfoStatusProvider)
+		apiKeyHandler  = handler.NewAPIKeyHandler(deps.APIKeyService)
+		mux            = chi.NewMux()
 	)
 
+	authEnabled := deps.APIKeyService != nil || deps.TokenVerifier != nil
+	scope := func(scopes ...string) func(http.Handler) http.Handler {
+		if !authEnabled {
+			return func(handler http.Handler) http.Handler { return handler }
+		}
+		return middleware.RequireScope(log, scopes...)
+	}
+
 	mux.Use(chiMiddleware.RequestID)
+	if deps.RateLimits.TrustProxy {
+		mux.Use(chiMiddleware.RealIP)
+	}
 	mux.Use(middleware.Logger(log))
-	mux.Use(middleware.Swagger(log))
+	mux.Use(middleware.CORS(log, deps.CORS))
+
+	if deps.APISpec != nil {
+		// clients see the spec under its base path, it differs from /api/v1 behind a proxy adding a prefix
+		docsHandler := handler.NewDocsHandler(deps.APISpec, path.Join("/", deps.APISpec.BasePath, "openapi.json"))
+
+		mux.Get("/api/v1/openapi.json", docsHandler.GetSpecJSON(log))
+		mux.Get("/api/v1/openapi.yaml", docsHandler.GetSpecYAML(log))
+		mux.Get("/docs", docsHandler.GetUI(log))
+		mux.Get("/docs/*", docsHandler.GetAsset(log))
+	}
 
 	mux.Route("/api/v1", func(r chi.Router) {
+		r.Use(middleware.RateLimitByIP(log, deps.RateLimits.IP))
+		if authEnabled {
+			r.Use(middleware.Auth(log, deps.APIKeyService, deps.TokenVerifier))
+		}
+		r.Use(middleware.RateLimit(log, deps.RateLimits.Read, deps.RateLimits.Write))
+		if deps.MaxBodySize > 0 {
+			r.Use(chiMiddleware.RequestSize(deps.MaxBodySize))
+		}
+		if deps.RequestValidator != nil {
+			r.Use(middleware.Validate(log, deps.RequestValidator))
+		}
+
 		r.Route("/cars", func(r chi.Router) {
-			r.Post("/", carHandler.AddNewCar(log))
-			r.Delete("/{reg_number}", carHandler.DeleteCar(log))
-			r.Put("/{reg_number}", carHandler.UpdateCar(log))
-			r.Get("/", carHandler.GetCars(log))
+			// adding cars adds their owners too
+			r.With(scope(model.ScopeCarsWrite, model.ScopeOwnersWrite)).Post("/", carHandler.AddNewCar(log))
+			r.With(scope(model.ScopeCarsDelete)).Delete("/{reg_number}", carHandler.DeleteCar(log))
+			r.With(scope(model.ScopeCarsWrite)).Put("/{reg_number}", carHandler.UpdateCar(log))
+			r.With(scope(model.ScopeCarsRead)).Get("/", carHandler.GetCars(log))
+			r.With(scope(model.ScopeCarsRead)).Get("/events", streamHandler.GetCarEvents(log))
+		})
+
+		r.Route("/searches", func(r chi.Router) {
+			r.Use(scope(model.ScopeCarsRead))
+
+			r.With(scope(model.ScopeSearchesWrite)).Post("/", searchHandler.AddSearch(log))
+			r.Get("/", searchHandler.GetSearches(log))
+			r.Get("/{id}/results", searchHandler.GetSearchResults(log))
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(scope(model.ScopeAdmin))
+
+			r.Route("/webhooks", func(r chi.Router) {
+				r.Post("/", webhookHandler.AddWebhook(log))
+				r.Get("/", webhookHandler.GetWebhooks(log))
+				r.Delete("/{id}", webhookHandler.DeleteWebhook(log))
+				r.Get("/{id}/deliveries", webhookHandler.GetDeliveries(log))
+			})
+
+			r.Get("/cache/stats", cacheHandler.GetStats(log))
+			r.Get("/carinfo/status", carInfoHandler.GetStatus(log))
+
+			r.Route("/admin/keys", func(r chi.Router) {
+				r.Post("/", apiKeyHandler.CreateAPIKey(log))
+				r.Get("/", apiKeyHandler.GetAPIKeys(log))
+				r.Delete("/{id}", apiKeyHandler.RevokeAPIKey(log))
+			})
 		})
 	})
 
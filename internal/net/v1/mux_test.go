@@ -0,0 +1,359 @@
+package v1_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	v1 "github.com/4aykovski/effective_mobile_test_task/internal/net/v1"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/apikeyservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/carservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/searchservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/webhookservice"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/jwt"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/response"
+)
+
+var jwtSecret = []byte("secret")
+
+type carService struct{}
+
+func (carService) AddNewCars(ctx context.Context, cars []carservice.AddNewCarInput, errs chan error) *sync.Map {
+	return &sync.Map{}
+}
+
+func (carService) DeleteCar(ctx context.Context, regNumber string) error {
+	return nil
+}
+
+func (carService) UpdateCar(ctx context.Context, car carservice.UpdateCarInput) error {
+	return nil
+}
+
+func (carService) GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options, sortFields []filter.SortField) ([]model.Car, error) {
+	return nil, nil
+}
+
+type searchService struct{}
+
+func (searchService) AddSearch(ctx context.Context, input searchservice.AddSearchInput) (model.SavedSearch, error) {
+	return model.SavedSearch{ID: 1, Name: input.Name}, nil
+}
+
+func (searchService) GetSearch(ctx context.Context, id int) (model.SavedSearch, error) {
+	return model.SavedSearch{ID: id}, nil
+}
+
+func (searchService) GetSearches(ctx context.Context) ([]model.SavedSearch, error) {
+	return nil, nil
+}
+
+type webhookService struct{}
+
+func (webhookService) AddWebhook(ctx context.Context, input webhookservice.AddWebhookInput) (model.Webhook, error) {
+	return model.Webhook{}, nil
+}
+
+func (webhookService) DeleteWebhook(ctx context.Context, id int) error {
+	return nil
+}
+
+func (webhookService) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	return nil, nil
+}
+
+func (webhookService) GetDeliveries(ctx context.Context, webhookID int) ([]model.WebhookDelivery, error) {
+	return nil, nil
+}
+
+// apiKeyService authenticates keys equal to their prefix.
+type apiKeyService struct {
+	keys []model.APIKey
+}
+
+func (s apiKeyService) CreateKey(ctx context.Context, input apikeyservice.CreateKeyInput) (model.APIKey, string, error) {
+	return model.APIKey{}, "", nil
+}
+
+func (s apiKeyService) GetKeys(ctx context.Context) ([]model.APIKey, error) {
+	return s.keys, nil
+}
+
+func (s apiKeyService) RevokeKey(ctx context.Context, id int) error {
+	return nil
+}
+
+func (s apiKeyService) Authenticate(ctx context.Context, value string) (model.APIKey, error) {
+	for _, key := range s.keys {
+		if key.Prefix == value {
+			return key, nil
+		}
+	}
+	return model.APIKey{}, apikeyservice.ErrInvalidKey
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	keys := apiKeyService{keys: []model.APIKey{
+		{ID: 1, Prefix: "reader", Scopes: []string{model.ScopeCarsRead}},
+		{ID: 2, Prefix: "admin", Scopes: []string{model.ScopeAdmin}},
+		{ID: 3, Prefix: "searcher", Scopes: []string{model.ScopeSearchesWrite}},
+	}}
+
+	mux := v1.NewMux(slog.New(slog.NewTextHandler(io.Discard, nil)), v1.Deps{
+		CarService:     carService{},
+		SearchService:  searchService{},
+		WebhookService: webhookService{},
+		APIKeyService:  keys,
+		TokenVerifier:  jwt.NewVerifier(jwt.Config{HMACSecret: jwtSecret}),
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func signToken(t *testing.T, claims map[string]any) string {
+	t.Helper()
+
+	encode := func(v any) string {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	signed := encode(map[string]string{"alg": jwt.AlgHS256, "typ": "JWT"}) + "." + encode(claims)
+	mac := hmac.New(sha256.New, jwtSecret)
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestScopes(t *testing.T) {
+	srv := newTestServer(t)
+
+	exp := time.Now().Add(time.Hour).Unix()
+	readDeleteToken := signToken(t, map[string]any{"sub": "operator", "exp": exp, "scope": "cars:read cars:delete"})
+	scopesToken := signToken(t, map[string]any{"sub": "analyst", "exp": exp, "scopes": []string{"cars:read", "searches:write"}})
+	adminToken := signToken(t, map[string]any{"sub": "root", "exp": exp, "scope": "admin"})
+	expiredToken := signToken(t, map[string]any{"sub": "operator", "exp": time.Now().Add(-time.Hour).Unix(), "scope": "admin"})
+
+	search := `{"name": "lada", "fields": {"mark": ["Lada"]}}`
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		apiKey     string
+		bearer     string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "no credentials",
+			method:     http.MethodGet,
+			path:       "/cars",
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "Unauthorized: credentials are required",
+		},
+		{
+			name:       "unknown api key",
+			method:     http.MethodGet,
+			path:       "/cars",
+			apiKey:     "unknown",
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "Unauthorized: invalid credentials",
+		},
+		{
+			name:       "expired token",
+			method:     http.MethodGet,
+			path:       "/cars",
+			bearer:     expiredToken,
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "Unauthorized: invalid credentials",
+		},
+		{
+			name:       "api key with scope",
+			method:     http.MethodGet,
+			path:       "/cars",
+			apiKey:     "reader",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "api key as bearer token",
+			method:     http.MethodGet,
+			path:       "/cars",
+			bearer:     "reader",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "api key without scope",
+			method:     http.MethodDelete,
+			path:       "/cars/X123XX150",
+			apiKey:     "reader",
+			wantStatus: http.StatusForbidden,
+			wantError:  "Forbidden: missing scope cars:delete",
+		},
+		{
+			name:       "jwt with scope",
+			method:     http.MethodDelete,
+			path:       "/cars/X123XX150",
+			bearer:     readDeleteToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "jwt without scope",
+			method:     http.MethodPut,
+			path:       "/cars/X123XX150",
+			body:       `{"year": 2015}`,
+			bearer:     readDeleteToken,
+			wantStatus: http.StatusForbidden,
+			wantError:  "Forbidden: missing scope cars:write",
+		},
+		{
+			name:       "adding cars requires owners write",
+			method:     http.MethodPost,
+			path:       "/cars",
+			body:       `{"regNumber": ["X123XX150"]}`,
+			bearer:     readDeleteToken,
+			wantStatus: http.StatusForbidden,
+			wantError:  "Forbidden: missing scope cars:write",
+		},
+		{
+			name:       "admin route",
+			method:     http.MethodGet,
+			path:       "/webhooks",
+			bearer:     readDeleteToken,
+			wantStatus: http.StatusForbidden,
+			wantError:  "Forbidden: missing scope admin",
+		},
+		{
+			name:       "admin api key on admin route",
+			method:     http.MethodGet,
+			path:       "/admin/keys",
+			apiKey:     "admin",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "admin api key on read route",
+			method:     http.MethodGet,
+			path:       "/cars",
+			apiKey:     "admin",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "admin api key on delete route",
+			method:     http.MethodDelete,
+			path:       "/cars/X123XX150",
+			apiKey:     "admin",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "admin jwt on write route",
+			method:     http.MethodPut,
+			path:       "/cars/X123XX150",
+			body:       `{"year": 2015}`,
+			bearer:     adminToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "admin jwt on searches",
+			method:     http.MethodPost,
+			path:       "/searches",
+			body:       search,
+			bearer:     adminToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "saving search without searches write",
+			method:     http.MethodPost,
+			path:       "/searches",
+			body:       search,
+			apiKey:     "reader",
+			wantStatus: http.StatusForbidden,
+			wantError:  "Forbidden: missing scope searches:write",
+		},
+		{
+			name:       "saving search without cars read",
+			method:     http.MethodPost,
+			path:       "/searches",
+			body:       search,
+			apiKey:     "searcher",
+			wantStatus: http.StatusForbidden,
+			wantError:  "Forbidden: missing scope cars:read",
+		},
+		{
+			name:       "saving search with both scopes",
+			method:     http.MethodPost,
+			path:       "/searches",
+			body:       search,
+			bearer:     scopesToken,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "reading searches without searches write",
+			method:     http.MethodGet,
+			path:       "/searches",
+			apiKey:     "reader",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+"/api/v1"+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("NewRequest: %v", err)
+			}
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do: %v", err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tt.wantStatus)
+			}
+			if tt.wantError == "" {
+				return
+			}
+
+			var body response.Response
+			if err = json.NewDecoder(res.Body).Decode(&body); err != nil {
+				t.Fatalf("can't decode response: %v", err)
+			}
+			if want := response.Error(tt.wantError); body != want {
+				t.Errorf("response = %+v, want %+v", body, want)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && res.Header.Get("WWW-Authenticate") != "Bearer" {
+				t.Errorf("WWW-Authenticate = %q, want Bearer", res.Header.Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
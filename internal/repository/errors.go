@@ -7,4 +7,11 @@ var (
 	ErrCarExists    = errors.New("car with this registration number already exists")
 	ErrCarNotFound  = errors.New("car with this registration number not found")
 	ErrCarsNotFound = errors.New("cars not found")
+
+	ErrSavedSearchExists   = errors.New("saved search with this name already exists")
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	ErrAPIKeyNotFound = errors.New("api key not found")
 )
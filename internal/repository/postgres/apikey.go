@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `id, name, prefix, hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+type APIKeyRepository struct {
+	postgres *postgres.Postgres
+}
+
+func NewAPIKeyRepository(postgres *postgres.Postgres) *APIKeyRepository {
+	return &APIKeyRepository{
+		postgres: postgres,
+	}
+}
+
+func (r *APIKeyRepository) InsertAPIKey(ctx context.Context, key model.APIKey) (model.APIKey, error) {
+	stmt, err := r.postgres.Prepare(
+		`INSERT INTO api_keys (name, prefix, hash, scopes, expires_at)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING id, created_at`,
+	)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to prepare add new api key statement: %w", err)
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, key.Name, key.Prefix, key.Hash, pq.Array(key.Scopes), key.ExpiresAt).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to execute add new api key statement: %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (model.APIKey, error) {
+	stmt, err := r.postgres.Prepare("SELECT " + apiKeyColumns + " FROM api_keys WHERE prefix = $1")
+	if err != nil {
+		return model.APIKey{}, fmt.Errorf("failed to prepare get api key statement: %w", err)
+	}
+	defer stmt.Close()
+
+	key, err := scanAPIKey(stmt.QueryRowContext(ctx, prefix))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.APIKey{}, repository.ErrAPIKeyNotFound
+		}
+
+		return model.APIKey{}, fmt.Errorf("failed to execute get api key statement: %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepository) GetAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	stmt, err := r.postgres.Prepare("SELECT " + apiKeyColumns + " FROM api_keys ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare get api keys statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get api keys statement: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey marks key as revoked, already revoked keys are reported as not found.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int) error {
+	stmt, err := r.postgres.Prepare("UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL")
+	if err != nil {
+		return fmt.Errorf("failed to prepare revoke api key statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute revoke api key statement: %w", err)
+	}
+
+	revoked, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if revoked == 0 {
+		return repository.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) UpdateAPIKeyLastUsed(ctx context.Context, id int, lastUsedAt time.Time) error {
+	stmt, err := r.postgres.Prepare("UPDATE api_keys SET last_used_at = $2 WHERE id = $1")
+	if err != nil {
+		return fmt.Errorf("failed to prepare update api key last used statement: %w", err)
+	}
+	defer stmt.Close()
+
+	if _, err = stmt.ExecContext(ctx, id, lastUsedAt); err != nil {
+		return fmt.Errorf("failed to execute update api key last used statement: %w", err)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (model.APIKey, error) {
+	var key model.APIKey
+
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, pq.Array(&key.Scopes),
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	return key, nil
+}
@@ -5,7 +5,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"sync"
 
 	"github.com/4aykovski/effective_mobile_test_task/internal/model"
 	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
@@ -25,18 +24,22 @@ func NewCarRepository(postgres *postgres.Postgres) *CarRepository {
 }
 
 func (r *CarRepository) InsertCar(ctx context.Context, car model.Car) error {
-	stmt, err := r.postgres.Prepare(
-		`INSERT INTO cars (registration_number, mark, model, year, owner_name, owner_surname) 
-  			 	VALUES ($1, $2, $3, $4, $5, $6)`,
+	tx, err := r.postgres.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		`INSERT INTO cars (registration_number, mark, model, year, owner_name, owner_surname, source) 
+  			 	VALUES ($1, $2, $3, $4, $5, $6, $7)`,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to prepare add new car statement: %w", err)
 	}
 	defer stmt.Close()
 
-	var mu sync.Mutex
-	mu.Lock()
-	_, err = stmt.ExecContext(ctx, car.RegistrationNumber, car.Mark, car.Model, car.Year, car.OwnerName, car.OwnerSurname)
+	_, err = stmt.ExecContext(ctx, car.RegistrationNumber, car.Mark, car.Model, car.Year, car.OwnerName, car.OwnerSurname, car.Source)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
@@ -47,38 +50,60 @@ func (r *CarRepository) InsertCar(ctx context.Context, car model.Car) error {
 
 		return fmt.Errorf("failed to execute add new car statement: %w", err)
 	}
-	mu.Unlock()
+
+	if err = insertOutboxMessage(ctx, tx, model.NewCarEvent(model.EventCarCreated, car)); err != nil {
+		return fmt.Errorf("failed to add car created event: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
 	return nil
 }
 
 func (r *CarRepository) DeleteCar(ctx context.Context, regNumber string) error {
-	stmt, err := r.postgres.Prepare("DELETE FROM cars WHERE registration_number = $1")
+	tx, err := r.postgres.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "DELETE FROM cars WHERE registration_number = $1 RETURNING *")
 	if err != nil {
 		return fmt.Errorf("failed to prepare delete car statement: %w", err)
 	}
 	defer stmt.Close()
 
-	res, err := stmt.ExecContext(ctx, regNumber)
+	var car model.Car
+	err = stmt.QueryRowContext(ctx, regNumber).Scan(&car.RegistrationNumber, &car.Mark, &car.Model, &car.Year, &car.OwnerName, &car.OwnerSurname, &car.Source)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrCarNotFound
+		}
+
 		return fmt.Errorf("failed to execute delete car statement: %w", err)
 	}
 
-	deleted, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+	if err = insertOutboxMessage(ctx, tx, model.NewCarEvent(model.EventCarDeleted, car)); err != nil {
+		return fmt.Errorf("failed to add car deleted event: %w", err)
 	}
 
-	if deleted == 0 {
-		return repository.ErrCarNotFound
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
 func (r *CarRepository) UpdateCar(ctx context.Context, car model.Car) error {
+	tx, err := r.postgres.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	stmt, err := r.postgres.Prepare(
+	stmt, err := tx.PrepareContext(ctx,
 		`UPDATE cars
 		SET mark = $1, model = $2, year = $3, owner_name = $4, owner_surname = $5
 		WHERE registration_number = $6`,
@@ -102,6 +127,14 @@ func (r *CarRepository) UpdateCar(ctx context.Context, car model.Car) error {
 		return repository.ErrCarNotFound
 	}
 
+	if err = insertOutboxMessage(ctx, tx, model.NewCarEvent(model.EventCarUpdated, car)); err != nil {
+		return fmt.Errorf("failed to add car updated event: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -114,7 +147,7 @@ func (r *CarRepository) GetCar(ctx context.Context, regNumber string) (model.Car
 	defer stmt.Close()
 
 	var car model.Car
-	err = stmt.QueryRowContext(ctx, regNumber).Scan(&car.RegistrationNumber, &car.Mark, &car.Model, &car.Year, &car.OwnerName, &car.OwnerSurname)
+	err = stmt.QueryRowContext(ctx, regNumber).Scan(&car.RegistrationNumber, &car.Mark, &car.Model, &car.Year, &car.OwnerName, &car.OwnerSurname, &car.Source)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return model.Car{}, repository.ErrCarNotFound
@@ -126,12 +159,18 @@ func (r *CarRepository) GetCar(ctx context.Context, regNumber string) (model.Car
 	return car, nil
 }
 
-func (r *CarRepository) GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options) ([]model.Car, error) {
+func (r *CarRepository) GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options, sortFields []filter.SortField) ([]model.Car, error) {
 	sqlStmt := `SELECT * FROM cars`
 	var args []interface{}
 
-	sqlStmt, args = postgres.AddFilterToStmt(sqlStmt, args, filterOptions, model.Car{})
-	sqlStmt += ` ORDER BY registration_number`
+	sqlStmt, args, err := postgres.AddFilterToStmt(sqlStmt, args, filterOptions, model.Car{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add filter to get cars statement: %w", err)
+	}
+	sqlStmt, err = postgres.AddSortToStmt(sqlStmt, sortFields, model.Car{}, "registration_number")
+	if err != nil {
+		return nil, fmt.Errorf("failed to add sort to get cars statement: %w", err)
+	}
 	sqlStmt, args = postgres.AddPaginationToStmt(sqlStmt, args, limit, offset)
 
 	stmt, err := r.postgres.Prepare(sqlStmt)
@@ -153,7 +192,7 @@ func (r *CarRepository) GetCars(ctx context.Context, limit, offset int, filterOp
 	var cars []model.Car
 	for rows.Next() {
 		var car model.Car
-		if err := rows.Scan(&car.RegistrationNumber, &car.Mark, &car.Model, &car.Year, &car.OwnerName, &car.OwnerSurname); err != nil {
+		if err := rows.Scan(&car.RegistrationNumber, &car.Mark, &car.Model, &car.Year, &car.OwnerName, &car.OwnerSurname, &car.Source); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 		cars = append(cars, car)
@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+)
+
+type CarInfoCacheRepository struct {
+	postgres *postgres.Postgres
+}
+
+func NewCarInfoCacheRepository(postgres *postgres.Postgres) *CarInfoCacheRepository {
+	return &CarInfoCacheRepository{
+		postgres: postgres,
+	}
+}
+
+func (r *CarInfoCacheRepository) GetCarInfo(ctx context.Context, regNumber string) (carinfo.CacheEntry, bool, error) {
+	stmt, err := r.postgres.Prepare(
+		`SELECT reg_number, payload, not_found, expires_at FROM car_info_cache WHERE reg_number = $1`,
+	)
+	if err != nil {
+		return carinfo.CacheEntry{}, false, fmt.Errorf("failed to prepare get car info statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var entry carinfo.CacheEntry
+	var payload []byte
+	err = stmt.QueryRowContext(ctx, regNumber).Scan(&entry.RegNumber, &payload, &entry.NotFound, &entry.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return carinfo.CacheEntry{}, false, nil
+		}
+
+		return carinfo.CacheEntry{}, false, fmt.Errorf("failed to execute get car info statement: %w", err)
+	}
+
+	if !entry.NotFound {
+		if err = json.Unmarshal(payload, &entry.Info); err != nil {
+			return carinfo.CacheEntry{}, false, fmt.Errorf("failed to unmarshal car info: %w", err)
+		}
+	}
+
+	return entry, true, nil
+}
+
+func (r *CarInfoCacheRepository) SetCarInfo(ctx context.Context, entry carinfo.CacheEntry) error {
+	var payload []byte
+	if !entry.NotFound {
+		var err error
+		payload, err = json.Marshal(entry.Info)
+		if err != nil {
+			return fmt.Errorf("failed to marshal car info: %w", err)
+		}
+	}
+
+	stmt, err := r.postgres.Prepare(
+		`INSERT INTO car_info_cache (reg_number, payload, not_found, expires_at)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (reg_number) DO UPDATE
+				SET payload = EXCLUDED.payload, not_found = EXCLUDED.not_found, expires_at = EXCLUDED.expires_at`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to prepare set car info statement: %w", err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, entry.RegNumber, payload, entry.NotFound, entry.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to execute set car info statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CarInfoCacheRepository) DeleteExpiredCarInfo(ctx context.Context, now time.Time) (int64, error) {
+	stmt, err := r.postgres.Prepare(`DELETE FROM car_info_cache WHERE expires_at <= $1`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare delete expired car info statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute delete expired car info statement: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get number of deleted car info: %w", err)
+	}
+
+	return deleted, nil
+}
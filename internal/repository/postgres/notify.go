@@ -0,0 +1,11 @@
+package postgres
+
+// Channels notified by triggers.
+const (
+	// CarsChangesChannel is notified on every change of cars table.
+	CarsChangesChannel = "cars_changes"
+	// OwnersChangesChannel is notified on every change of owners table.
+	OwnersChangesChannel = "owners_changes"
+	// OutboxMessagesChannel is notified when messages are inserted into the outbox.
+	OutboxMessagesChannel = "outbox_messages"
+)
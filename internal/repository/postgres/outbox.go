@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+	"github.com/lib/pq"
+)
+
+const outboxColumns = `id, aggregate_type, aggregate_id, event_type, payload, status, attempts, last_error, published_to, next_attempt_at, created_at, published_at`
+
+type OutboxRepository struct {
+	postgres *postgres.Postgres
+}
+
+func NewOutboxRepository(postgres *postgres.Postgres) *OutboxRepository {
+	return &OutboxRepository{
+		postgres: postgres,
+	}
+}
+
+// ClaimDueMessages returns up to limit pending messages whose next attempt is due and postpones them by lease,
+// so concurrent relays don't publish the same message while it is in flight. Only the oldest pending message
+// of every aggregate is taken, so messages of one aggregate are published in order. Dead messages don't block
+// their aggregate.
+func (r *OutboxRepository) ClaimDueMessages(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxMessage, error) {
+	stmt, err := r.postgres.Prepare(
+		`UPDATE outbox SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM outbox o
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox p
+					WHERE p.status = 'pending'
+						AND p.aggregate_type = o.aggregate_type
+						AND p.aggregate_id = o.aggregate_id
+						AND p.id < o.id
+				)
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare claim outbox messages statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute claim outbox messages statement: %w", err)
+	}
+	defer rows.Close()
+
+	messages, err := scanOutboxMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING doesn't keep order of the subquery
+	slices.SortFunc(messages, func(a, b model.OutboxMessage) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return messages, nil
+}
+
+func (r *OutboxRepository) UpdateMessage(ctx context.Context, msg model.OutboxMessage) error {
+	stmt, err := r.postgres.Prepare(
+		`UPDATE outbox
+		SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, published_to = $5, published_at = $6
+		WHERE id = $7`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to prepare update outbox message statement: %w", err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, msg.Status, msg.Attempts, msg.LastError, msg.NextAttemptAt, pq.Array(msg.PublishedTo), msg.PublishedAt, msg.ID)
+	if err != nil {
+		return fmt.Errorf("failed to execute update outbox message statement: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePublishedMessages deletes up to limit messages published before the given time and returns their number.
+func (r *OutboxRepository) DeletePublishedMessages(ctx context.Context, before time.Time, limit int) (int64, error) {
+	stmt, err := r.postgres.Prepare(
+		`DELETE FROM outbox
+		WHERE id IN (SELECT id FROM outbox WHERE status = 'published' AND published_at < $1 LIMIT $2)`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare delete outbox messages statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute delete outbox messages statement: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get number of deleted outbox messages: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// GetLastMessageID returns the greatest id of visible messages, zero if the outbox is empty.
+func (r *OutboxRepository) GetLastMessageID(ctx context.Context) (int64, error) {
+	var id int64
+	if err := r.postgres.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM outbox`).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to execute get last outbox message id statement: %w", err)
+	}
+
+	return id, nil
+}
+
+// GetMessagesAfter returns up to limit messages with ids greater than id except ids in skip, ordered by id.
+func (r *OutboxRepository) GetMessagesAfter(ctx context.Context, id int64, skip []int64, limit int) ([]model.OutboxMessage, error) {
+	rows, err := r.postgres.QueryContext(ctx,
+		`SELECT `+outboxColumns+` FROM outbox WHERE id > $1 AND NOT (id = ANY($2)) ORDER BY id LIMIT $3`,
+		id, pq.Array(skip), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get outbox messages statement: %w", err)
+	}
+	defer rows.Close()
+
+	return scanOutboxMessages(rows)
+}
+
+func scanOutboxMessages(rows *sql.Rows) ([]model.OutboxMessage, error) {
+	var messages []model.OutboxMessage
+	for rows.Next() {
+		var msg model.OutboxMessage
+		err := rows.Scan(&msg.ID, &msg.AggregateType, &msg.AggregateID, &msg.EventType, &msg.Payload, &msg.Status,
+			&msg.Attempts, &msg.LastError, pq.Array(&msg.PublishedTo), &msg.NextAttemptAt, &msg.CreatedAt, &msg.PublishedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// insertOutboxMessage stores event in the outbox as part of tx.
+func insertOutboxMessage(ctx context.Context, tx *sql.Tx, event model.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	aggregateType, aggregateID := event.Aggregate()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload)
+				VALUES ($1, $2, $3, $4)`,
+		aggregateType, aggregateID, event.Type, payload,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to execute add outbox message statement: %w", err)
+	}
+
+	return nil
+}
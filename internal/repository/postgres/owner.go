@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"sync"
 
 	"github.com/4aykovski/effective_mobile_test_task/internal/model"
 	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
@@ -23,7 +22,13 @@ func NewOwnerRepository(postgres *postgres.Postgres) *OwnerRepository {
 }
 
 func (r *OwnerRepository) InsertOwner(ctx context.Context, owner model.Owner) error {
-	stmt, err := r.postgres.Prepare(
+	tx, err := r.postgres.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
 		`INSERT INTO owners (name, surname, patronymic)
   			 	VALUES ($1, $2, $3)`,
 	)
@@ -32,8 +37,6 @@ func (r *OwnerRepository) InsertOwner(ctx context.Context, owner model.Owner) er
 	}
 	defer stmt.Close()
 
-	var mu sync.Mutex
-	mu.Lock()
 	_, err = stmt.ExecContext(ctx, owner.Name, owner.Surname, owner.Patronymic)
 	if err != nil {
 		var pqErr *pq.Error
@@ -45,7 +48,14 @@ func (r *OwnerRepository) InsertOwner(ctx context.Context, owner model.Owner) er
 
 		return fmt.Errorf("failed to execute add new owner statement: %w", err)
 	}
-	mu.Unlock()
+
+	if err = insertOutboxMessage(ctx, tx, model.NewOwnerEvent(model.EventOwnerCreated, owner)); err != nil {
+		return fmt.Errorf("failed to add owner created event: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
 	return nil
 }
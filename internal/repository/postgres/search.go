@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+	"github.com/lib/pq"
+)
+
+type SavedSearchRepository struct {
+	postgres *postgres.Postgres
+}
+
+func NewSavedSearchRepository(postgres *postgres.Postgres) *SavedSearchRepository {
+	return &SavedSearchRepository{
+		postgres: postgres,
+	}
+}
+
+func (r *SavedSearchRepository) InsertSavedSearch(ctx context.Context, search model.SavedSearch) (model.SavedSearch, error) {
+	fields, err := json.Marshal(search.Fields)
+	if err != nil {
+		return model.SavedSearch{}, fmt.Errorf("failed to marshal saved search fields: %w", err)
+	}
+
+	stmt, err := r.postgres.Prepare(
+		`INSERT INTO saved_searches (name, filter, fields, sort, page_limit, page_offset)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING id, created_at`,
+	)
+	if err != nil {
+		return model.SavedSearch{}, fmt.Errorf("failed to prepare add new saved search statement: %w", err)
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, search.Name, search.Filter, fields, search.Sort, search.Limit, search.Offset).Scan(&search.ID, &search.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
+			if pqErr.Code.Name() == "unique_violation" {
+				return model.SavedSearch{}, repository.ErrSavedSearchExists
+			}
+		}
+
+		return model.SavedSearch{}, fmt.Errorf("failed to execute add new saved search statement: %w", err)
+	}
+
+	return search, nil
+}
+
+func (r *SavedSearchRepository) GetSavedSearch(ctx context.Context, id int) (model.SavedSearch, error) {
+	stmt, err := r.postgres.Prepare(
+		`SELECT id, name, filter, fields, sort, page_limit, page_offset, created_at FROM saved_searches WHERE id = $1`,
+	)
+	if err != nil {
+		return model.SavedSearch{}, fmt.Errorf("failed to prepare get saved search statement: %w", err)
+	}
+	defer stmt.Close()
+
+	search, err := scanSavedSearch(stmt.QueryRowContext(ctx, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.SavedSearch{}, repository.ErrSavedSearchNotFound
+		}
+
+		return model.SavedSearch{}, fmt.Errorf("failed to execute get saved search statement: %w", err)
+	}
+
+	return search, nil
+}
+
+func (r *SavedSearchRepository) GetSavedSearches(ctx context.Context) ([]model.SavedSearch, error) {
+	stmt, err := r.postgres.Prepare(
+		`SELECT id, name, filter, fields, sort, page_limit, page_offset, created_at FROM saved_searches ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare get saved searches statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get saved searches statement: %w", err)
+	}
+	defer rows.Close()
+
+	var searches []model.SavedSearch
+	for rows.Next() {
+		search, err := scanSavedSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		searches = append(searches, search)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate saved searches: %w", err)
+	}
+
+	return searches, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSavedSearch(row rowScanner) (model.SavedSearch, error) {
+	var (
+		search model.SavedSearch
+		fields []byte
+	)
+
+	err := row.Scan(&search.ID, &search.Name, &search.Filter, &fields, &search.Sort, &search.Limit, &search.Offset, &search.CreatedAt)
+	if err != nil {
+		return model.SavedSearch{}, err
+	}
+
+	if err = json.Unmarshal(fields, &search.Fields); err != nil {
+		return model.SavedSearch{}, fmt.Errorf("failed to unmarshal saved search fields: %w", err)
+	}
+
+	return search, nil
+}
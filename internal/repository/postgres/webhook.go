@@ -0,0 +1,220 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+	"github.com/lib/pq"
+)
+
+const deliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, updated_at`
+
+type WebhookRepository struct {
+	postgres *postgres.Postgres
+}
+
+func NewWebhookRepository(postgres *postgres.Postgres) *WebhookRepository {
+	return &WebhookRepository{
+		postgres: postgres,
+	}
+}
+
+func (r *WebhookRepository) InsertWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error) {
+	stmt, err := r.postgres.Prepare(
+		`INSERT INTO webhooks (url, secret, events)
+				VALUES ($1, $2, $3)
+				RETURNING id, created_at`,
+	)
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("failed to prepare add new webhook statement: %w", err)
+	}
+	defer stmt.Close()
+
+	err = stmt.QueryRowContext(ctx, webhook.URL, webhook.Secret, pq.Array(webhook.Events)).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("failed to execute add new webhook statement: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id int) error {
+	stmt, err := r.postgres.Prepare("DELETE FROM webhooks WHERE id = $1")
+	if err != nil {
+		return fmt.Errorf("failed to prepare delete webhook statement: %w", err)
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to execute delete webhook statement: %w", err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if deleted == 0 {
+		return repository.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetWebhook(ctx context.Context, id int) (model.Webhook, error) {
+	stmt, err := r.postgres.Prepare("SELECT id, url, secret, events, created_at FROM webhooks WHERE id = $1")
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("failed to prepare get webhook statement: %w", err)
+	}
+	defer stmt.Close()
+
+	var webhook model.Webhook
+	err = stmt.QueryRowContext(ctx, id).Scan(&webhook.ID, &webhook.URL, &webhook.Secret, pq.Array(&webhook.Events), &webhook.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Webhook{}, repository.ErrWebhookNotFound
+		}
+
+		return model.Webhook{}, fmt.Errorf("failed to execute get webhook statement: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhookRepository) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	stmt, err := r.postgres.Prepare("SELECT id, url, secret, events, created_at FROM webhooks ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare get webhooks statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get webhooks statement: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []model.Webhook
+	for rows.Next() {
+		var webhook model.Webhook
+		if err := rows.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, pq.Array(&webhook.Events), &webhook.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) InsertDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	stmt, err := r.postgres.Prepare(
+		`INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status)
+				VALUES ($1, $2, $3, $4, $5)`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to prepare add new delivery statement: %w", err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload, delivery.Status)
+	if err != nil {
+		return fmt.Errorf("failed to execute add new delivery statement: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimDueDeliveries returns up to limit pending deliveries whose next attempt is due and postpones them by lease,
+// so concurrent workers don't send the same delivery twice while it is in flight.
+func (r *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	stmt, err := r.postgres.Prepare(
+		`UPDATE webhook_deliveries SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare claim deliveries statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute claim deliveries statement: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error {
+	stmt, err := r.postgres.Prepare(
+		`UPDATE webhook_deliveries
+		SET status = $1, attempts = $2, response_status = $3, last_error = $4, next_attempt_at = $5, updated_at = NOW()
+		WHERE id = $6`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to prepare update delivery statement: %w", err)
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.LastError, delivery.NextAttemptAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("failed to execute update delivery statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, webhookID int) ([]model.WebhookDelivery, error) {
+	stmt, err := r.postgres.Prepare(
+		`SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare get deliveries statement: %w", err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.QueryContext(ctx, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get deliveries statement: %w", err)
+	}
+	defer rows.Close()
+
+	return scanDeliveries(rows)
+}
+
+func scanDeliveries(rows *sql.Rows) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		var d model.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,150 @@
+package apikeyservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
+)
+
+// keyPrefix starts every key, so leaked keys are easy to find with secret scanners.
+const keyPrefix = "ck_"
+
+// lastUsedPrecision limits how often usage of a key is written to the database.
+const lastUsedPrecision = time.Minute
+
+var ErrInvalidKey = errors.New("invalid api key")
+
+type apiKeyRepository interface {
+	InsertAPIKey(ctx context.Context, key model.APIKey) (model.APIKey, error)
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (model.APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int) error
+	UpdateAPIKeyLastUsed(ctx context.Context, id int, lastUsedAt time.Time) error
+}
+
+type Service struct {
+	log              *slog.Logger
+	apiKeyRepository apiKeyRepository
+}
+
+func New(log *slog.Logger, apiKeyRepository apiKeyRepository) *Service {
+	return &Service{
+		log:              log,
+		apiKeyRepository: apiKeyRepository,
+	}
+}
+
+type CreateKeyInput struct {
+	Name   string
+	Scopes []string
+	// ExpiresAt is nil for keys that never expire.
+	ExpiresAt *time.Time
+}
+
+// CreateKey generates new key and returns it with its plain text value. Only hash of the value is stored,
+// so it can't be shown again.
+func (s *Service) CreateKey(ctx context.Context, input CreateKeyInput) (model.APIKey, string, error) {
+	prefix, err := randomHex(8)
+	if err != nil {
+		return model.APIKey{}, "", fmt.Errorf("failed to generate api key prefix: %w", err)
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return model.APIKey{}, "", fmt.Errorf("failed to generate api key secret: %w", err)
+	}
+
+	scopes := input.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	key, err := s.apiKeyRepository.InsertAPIKey(ctx, model.APIKey{
+		Name:      input.Name,
+		Prefix:    keyPrefix + prefix,
+		Hash:      hashSecret(secret),
+		Scopes:    scopes,
+		ExpiresAt: input.ExpiresAt,
+	})
+	if err != nil {
+		return model.APIKey{}, "", fmt.Errorf("failed to add api key: %w", err)
+	}
+
+	return key, key.Prefix + "_" + secret, nil
+}
+
+func (s *Service) GetKeys(ctx context.Context) ([]model.APIKey, error) {
+	keys, err := s.apiKeyRepository.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *Service) RevokeKey(ctx context.Context, id int) error {
+	if err := s.apiKeyRepository.RevokeAPIKey(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	return nil
+}
+
+// Authenticate returns key with the given plain text value. Unknown, revoked and expired keys are reported
+// with ErrInvalidKey.
+func (s *Service) Authenticate(ctx context.Context, value string) (model.APIKey, error) {
+	i := strings.LastIndexByte(value, '_')
+	if i < 0 || !strings.HasPrefix(value, keyPrefix) {
+		return model.APIKey{}, ErrInvalidKey
+	}
+	prefix, secret := value[:i], value[i+1:]
+
+	key, err := s.apiKeyRepository.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			return model.APIKey{}, ErrInvalidKey
+		}
+
+		return model.APIKey{}, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare(key.Hash, hashSecret(secret)) != 1 || !key.Active(now) {
+		return model.APIKey{}, ErrInvalidKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedPrecision {
+		// usage is informational, so the key stays valid even if it can't be recorded
+		if err = s.apiKeyRepository.UpdateAPIKeyLastUsed(ctx, key.ID, now); err != nil {
+			s.log.Warn("failed to update api key last used", slog.Int("api_key_id", key.ID), slog.String("error", err.Error()))
+		} else {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, nil
+}
+
+func randomHex(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
+
+func hashSecret(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
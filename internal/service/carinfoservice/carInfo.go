@@ -2,52 +2,174 @@ package carinfoservice
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"sync"
 
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client"
 	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
 )
 
+const (
+	StatusOK                  = "ok"
+	StatusNotFound            = "not_found"
+	StatusBadRequest          = "bad_request"
+	StatusUnauthorized        = "unauthorized"
+	StatusUpstreamError       = "upstream_error"
+	StatusUpstreamUnavailable = "upstream_unavailable"
+	StatusTimeout             = "timeout"
+	StatusMalformed           = "malformed"
+	StatusCancelled           = "cancelled"
+	StatusError               = "error"
+)
+
 type сarInfoClient interface {
-	GetCarInfoByRegNumber(ctx context.Context, number string) ([]byte, error)
+	GetCarInfoByRegNumber(ctx context.Context, number string) (carinfo.CarInfo, error)
+}
+
+// Provider is a named source of car info.
+type Provider interface {
+	сarInfoClient
+	Name() string
+}
+
+type provider struct {
+	сarInfoClient
+	name string
+}
+
+func (p provider) Name() string {
+	return p.name
+}
+
+func NewProvider(name string, client сarInfoClient) Provider {
+	return provider{
+		сarInfoClient: client,
+		name:          name,
+	}
+}
+
+// Result is info about a single registration number. Status is StatusOK or classifies Err.
+// Provider is name of the provider which supplied the info or, on failure, gave the reported error.
+type Result struct {
+	RegNumber string
+	Info      carinfo.CarInfo
+	Provider  string
+	Status    string
+	Err       error
 }
 
 type Service struct {
-	client сarInfoClient
+	providers   []Provider
+	concurrency int
 }
 
-func New(client сarInfoClient) *Service {
+// New returns service asking providers in the given order until one of them knows the car.
+// At most concurrency registration numbers are processed at once.
+func New(providers []Provider, concurrency int) *Service {
 	return &Service{
-		client: client,
+		providers:   providers,
+		concurrency: max(concurrency, 1),
 	}
 }
 
-func (service *Service) GetCarInfoByRegNumber(ctx context.Context, regNumbers []string, errs chan error) map[string]carinfo.CarInfo {
-	carInfos := make(map[string]carinfo.CarInfo)
-	var wg sync.WaitGroup
-	wg.Add(len(regNumbers))
+// GetCarInfoByRegNumber returns one result per distinct registration number in order of their first appearance.
+// When ctx is done no new requests are made and numbers which weren't requested get StatusCancelled.
+func (service *Service) GetCarInfoByRegNumber(ctx context.Context, regNumbers []string) []Result {
+	regNumbers = distinct(regNumbers)
+	results := make([]Result, len(regNumbers))
 
-	for _, regNumber := range regNumbers {
-		go func(regNumber string) {
+	// every worker writes only results of indexes it received, so results need no lock
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(service.concurrency, len(regNumbers)) {
+		wg.Add(1)
+		go func() {
 			defer wg.Done()
-			res, err := service.client.GetCarInfoByRegNumber(ctx, regNumber)
-			if err != nil {
-				errs <- err
-				carInfos[regNumber] = carinfo.CarInfo{}
-				return
-			}
-			var carInfo carinfo.CarInfo
-			if err = json.Unmarshal(res, &carInfo); err != nil {
-				errs <- err
-				carInfos[regNumber] = carinfo.CarInfo{}
-				return
+			for i := range jobs {
+				results[i] = service.getCarInfo(ctx, regNumbers[i])
 			}
-			carInfos[regNumber] = carInfo
-		}(regNumber)
+		}()
 	}
 
+	next := 0
+feed:
+	for ; next < len(regNumbers); next++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- next:
+		}
+	}
+	close(jobs)
 	wg.Wait()
-	close(errs)
 
-	return carInfos
+	for i := next; i < len(regNumbers); i++ {
+		results[i] = Result{RegNumber: regNumbers[i], Status: StatusCancelled, Err: ctx.Err()}
+	}
+
+	return results
+}
+
+// getCarInfo returns the first successful result. If all providers fail, the first failure other than
+// StatusNotFound wins, because the car could be known to the provider which failed.
+func (service *Service) getCarInfo(ctx context.Context, regNumber string) Result {
+	failed := Result{RegNumber: regNumber, Status: StatusError, Err: errors.New("no car info providers")}
+
+	for i, provider := range service.providers {
+		result := service.getCarInfoFrom(ctx, provider, regNumber)
+		if result.Status == StatusOK || result.Status == StatusCancelled {
+			return result
+		}
+
+		if i == 0 || (failed.Status == StatusNotFound && result.Status != StatusNotFound) {
+			failed = result
+		}
+	}
+
+	return failed
+}
+
+func (service *Service) getCarInfoFrom(ctx context.Context, provider Provider, regNumber string) Result {
+	carInfo, err := provider.GetCarInfoByRegNumber(ctx, regNumber)
+	if err != nil {
+		return Result{RegNumber: regNumber, Provider: provider.Name(), Status: classify(ctx, err), Err: err}
+	}
+
+	return Result{RegNumber: regNumber, Info: carInfo, Provider: provider.Name(), Status: StatusOK}
+}
+
+func classify(ctx context.Context, err error) string {
+	switch {
+	case ctx.Err() != nil:
+		return StatusCancelled
+	case errors.Is(err, client.ErrUpstreamUnavailable):
+		return StatusUpstreamUnavailable
+	case errors.Is(err, carinfo.ErrNotFound):
+		return StatusNotFound
+	case errors.Is(err, carinfo.ErrBadRequest):
+		return StatusBadRequest
+	case errors.Is(err, carinfo.ErrUnauthorized):
+		return StatusUnauthorized
+	case errors.Is(err, carinfo.ErrTimeout):
+		return StatusTimeout
+	case errors.Is(err, carinfo.ErrMalformed):
+		return StatusMalformed
+	case errors.Is(err, carinfo.ErrUpstream):
+		return StatusUpstreamError
+	default:
+		return StatusError
+	}
+}
+
+func distinct(regNumbers []string) []string {
+	seen := make(map[string]struct{}, len(regNumbers))
+	res := make([]string, 0, len(regNumbers))
+	for _, regNumber := range regNumbers {
+		if _, ok := seen[regNumber]; ok {
+			continue
+		}
+		seen[regNumber] = struct{}{}
+		res = append(res, regNumber)
+	}
+	return res
 }
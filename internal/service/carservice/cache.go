@@ -0,0 +1,171 @@
+package carservice
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/cache"
+)
+
+type CacheStats struct {
+	Cars  cache.Stats `json:"cars"`
+	Lists cache.Stats `json:"lists"`
+}
+
+// CachedRepository is a read-through cache around carRepository. Single cars and pages of GetCars
+// are cached, any mutation made through the repository invalidates affected entries.
+type CachedRepository struct {
+	carRepository carRepository
+	cars          *cache.LRU[string, model.Car]
+	lists         *cache.LRU[string, []model.Car]
+
+	// generations are bumped on every invalidation, so results read from the database
+	// before an invalidation are not put into the cache after it. mu makes comparing generation and
+	// putting result into the cache atomic with respect to invalidations
+	mu              sync.Mutex
+	carsGeneration  uint64
+	listsGeneration uint64
+}
+
+func NewCachedRepository(carRepository carRepository, carsSize, listsSize int, ttl time.Duration) *CachedRepository {
+	return &CachedRepository{
+		carRepository: carRepository,
+		cars:          cache.NewLRU[string, model.Car](carsSize, ttl),
+		lists:         cache.NewLRU[string, []model.Car](listsSize, ttl),
+	}
+}
+
+func (r *CachedRepository) InsertCar(ctx context.Context, car model.Car) error {
+	err := r.carRepository.InsertCar(ctx, car)
+	r.InvalidateLists()
+	return err
+}
+
+func (r *CachedRepository) DeleteCar(ctx context.Context, regNumber string) error {
+	err := r.carRepository.DeleteCar(ctx, regNumber)
+	r.InvalidateCar(regNumber)
+	return err
+}
+
+func (r *CachedRepository) UpdateCar(ctx context.Context, car model.Car) error {
+	err := r.carRepository.UpdateCar(ctx, car)
+	r.InvalidateCar(car.RegistrationNumber)
+	return err
+}
+
+func (r *CachedRepository) GetCar(ctx context.Context, regNumber string) (model.Car, error) {
+	if car, ok := r.cars.Get(regNumber); ok {
+		return car, nil
+	}
+
+	r.mu.Lock()
+	generation := r.carsGeneration
+	r.mu.Unlock()
+
+	car, err := r.carRepository.GetCar(ctx, regNumber)
+	if err != nil {
+		return model.Car{}, err
+	}
+
+	r.mu.Lock()
+	if r.carsGeneration == generation {
+		r.cars.Set(regNumber, car)
+	}
+	r.mu.Unlock()
+
+	return car, nil
+}
+
+func (r *CachedRepository) GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options, sortFields []filter.SortField) ([]model.Car, error) {
+	key := listKey(limit, offset, filterOptions, sortFields)
+
+	if cars, ok := r.lists.Get(key); ok {
+		return slices.Clone(cars), nil
+	}
+
+	r.mu.Lock()
+	generation := r.listsGeneration
+	r.mu.Unlock()
+
+	cars, err := r.carRepository.GetCars(ctx, limit, offset, filterOptions, sortFields)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.listsGeneration == generation {
+		r.lists.Set(key, slices.Clone(cars))
+	}
+	r.mu.Unlock()
+
+	return cars, nil
+}
+
+// InvalidateCar removes the car and all cached pages, since the car could be in any of them.
+func (r *CachedRepository) InvalidateCar(regNumber string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.carsGeneration++
+	r.cars.Delete(regNumber)
+	r.invalidateLists()
+}
+
+func (r *CachedRepository) InvalidateLists() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.invalidateLists()
+}
+
+// InvalidateAll removes every cached entry.
+func (r *CachedRepository) InvalidateAll() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.carsGeneration++
+	r.cars.Purge()
+	r.invalidateLists()
+}
+
+// invalidateLists must be called with mu held.
+func (r *CachedRepository) invalidateLists() {
+	r.listsGeneration++
+	r.lists.Purge()
+}
+
+func (r *CachedRepository) Stats() CacheStats {
+	return CacheStats{
+		Cars:  r.cars.Stats(),
+		Lists: r.lists.Stats(),
+	}
+}
+
+func listKey(limit, offset int, filterOptions filter.Options, sortFields []filter.SortField) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d|%d", limit, offset)
+
+	for _, field := range sortFields {
+		fmt.Fprintf(&sb, "|sort:%s", field)
+	}
+
+	if filterOptions == nil {
+		return sb.String()
+	}
+
+	for _, field := range filterOptions.Fields() {
+		fmt.Fprintf(&sb, "|%s:%s:%q", field.Name, field.Op, field.Value)
+	}
+
+	if expr := filterOptions.Expression(); expr != nil {
+		fmt.Fprintf(&sb, "|%s", expr)
+	}
+
+	return sb.String()
+}
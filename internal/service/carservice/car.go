@@ -14,7 +14,7 @@ type carRepository interface {
 	DeleteCar(ctx context.Context, regNumber string) error
 	UpdateCar(ctx context.Context, car model.Car) error
 	GetCar(ctx context.Context, regNumber string) (model.Car, error)
-	GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options) ([]model.Car, error)
+	GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options, sortFields []filter.SortField) ([]model.Car, error)
 }
 
 type Service struct {
@@ -64,6 +64,7 @@ type AddNewCarInput struct {
 	Year               int
 	OwnerName          string
 	OwnerSurname       string
+	Source             string
 	Valid              bool
 }
 
@@ -76,6 +77,7 @@ func (s *Service) AddNewCar(ctx context.Context, car AddNewCarInput) error {
 		Year:               car.Year,
 		OwnerName:          car.OwnerName,
 		OwnerSurname:       car.OwnerSurname,
+		Source:             car.Source,
 	}
 
 	if err := s.carRepository.InsertCar(ctx, carInfo); err != nil {
@@ -117,6 +119,7 @@ func (s *Service) UpdateCar(ctx context.Context, car UpdateCarInput) error {
 		Year:               car.Year,
 		OwnerName:          car.OwnerName,
 		OwnerSurname:       car.OwnerSurname,
+		Source:             oldCar.Source,
 	}
 
 	if car.Year == 0 {
@@ -146,8 +149,8 @@ func (s *Service) UpdateCar(ctx context.Context, car UpdateCarInput) error {
 	return nil
 }
 
-func (s *Service) GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options) ([]model.Car, error) {
-	cars, err := s.carRepository.GetCars(ctx, limit, offset, filterOptions)
+func (s *Service) GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options, sortFields []filter.SortField) ([]model.Car, error) {
+	cars, err := s.carRepository.GetCars(ctx, limit, offset, filterOptions, sortFields)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cars: %w", err)
 	}
@@ -0,0 +1,202 @@
+package outboxservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/config"
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+)
+
+const pruneBatchSize = 1000
+
+// errMalformedMessage means the message can't be published ever, so it isn't retried.
+var errMalformedMessage = errors.New("malformed outbox message")
+
+// Publisher delivers events read from the outbox. Publish may be called more than once for the same event,
+// so publishers should use event id to deduplicate if they need exactly-once processing.
+type Publisher interface {
+	Publish(ctx context.Context, event model.Event) error
+}
+
+type outboxRepository interface {
+	ClaimDueMessages(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxMessage, error)
+	UpdateMessage(ctx context.Context, msg model.OutboxMessage) error
+	DeletePublishedMessages(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// Relay moves events from the outbox to the publishers. Every publisher is tracked separately, so retries
+// after failure of one publisher don't publish the event to the others again.
+type Relay struct {
+	log              *slog.Logger
+	outboxRepository outboxRepository
+	publishers       map[string]Publisher
+	names            []string
+	cfg              config.OutboxConfig
+}
+
+// New returns relay to publishers by their names. Names are stored with published messages, so they must not
+// change between restarts.
+func New(log *slog.Logger, outboxRepository outboxRepository, publishers map[string]Publisher, cfg config.OutboxConfig) *Relay {
+	names := make([]string, 0, len(publishers))
+	for name := range publishers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return &Relay{
+		log:              log.With(slog.String("service", "outbox")),
+		outboxRepository: outboxRepository,
+		publishers:       publishers,
+		names:            names,
+		cfg:              cfg,
+	}
+}
+
+// Run publishes due outbox messages and deletes published ones older than retention until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	r.log.Info("outbox relay started")
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var prune <-chan time.Time
+	if r.cfg.Retention > 0 {
+		pruneTicker := time.NewTicker(r.cfg.PruneInterval)
+		defer pruneTicker.Stop()
+		prune = pruneTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("outbox relay stopped")
+			return
+		case <-ticker.C:
+			r.relayDue(ctx)
+		case <-prune:
+			r.prune(ctx)
+		}
+	}
+}
+
+// prune deletes published messages older than retention in batches, so the outbox isn't locked for long.
+func (r *Relay) prune(ctx context.Context) {
+	before := time.Now().Add(-r.cfg.Retention)
+
+	var total int64
+	for ctx.Err() == nil {
+		deleted, err := r.outboxRepository.DeletePublishedMessages(ctx, before, pruneBatchSize)
+		if err != nil {
+			r.log.Error("failed to delete published outbox messages", slog.String("error", err.Error()))
+			return
+		}
+
+		total += deleted
+		if deleted < pruneBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		r.log.Info("published outbox messages deleted", slog.Int64("count", total))
+	}
+}
+
+// relayDue handles batches until there is nothing left that can be published right now. Messages are claimed
+// for the lease, so publishers are called without holding locks in the database.
+func (r *Relay) relayDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		messages, err := r.outboxRepository.ClaimDueMessages(ctx, r.cfg.BatchSize, r.cfg.Lease)
+		if err != nil {
+			r.log.Error("failed to claim outbox messages", slog.String("error", err.Error()))
+			return
+		}
+
+		if len(messages) == 0 {
+			return
+		}
+
+		for _, msg := range messages {
+			r.handle(ctx, msg)
+		}
+		r.log.Debug("outbox messages handled", slog.Int("count", len(messages)))
+	}
+}
+
+func (r *Relay) handle(ctx context.Context, msg model.OutboxMessage) {
+	log := r.log.With(
+		slog.Int64("outbox_id", msg.ID),
+		slog.String("event_type", msg.EventType),
+		slog.String("aggregate_type", msg.AggregateType),
+		slog.String("aggregate_id", msg.AggregateID),
+	)
+
+	msg.Attempts++
+	err := r.publish(ctx, &msg)
+
+	switch {
+	case err == nil:
+		now := time.Now()
+		msg.Status = model.OutboxStatusPublished
+		msg.LastError = ""
+		msg.PublishedAt = &now
+	case errors.Is(err, errMalformedMessage) || msg.Attempts >= r.cfg.MaxAttempts:
+		msg.Status = model.OutboxStatusDead
+		msg.LastError = err.Error()
+		log.Error("outbox message moved to dead letter", slog.Int("attempts", msg.Attempts), slog.String("error", err.Error()))
+	default:
+		msg.LastError = err.Error()
+		msg.NextAttemptAt = time.Now().Add(r.backoff(msg.Attempts))
+		log.Info("failed to publish event, will retry",
+			slog.Int("attempts", msg.Attempts),
+			slog.Time("next_attempt_at", msg.NextAttemptAt),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	if err = r.outboxRepository.UpdateMessage(context.WithoutCancel(ctx), msg); err != nil {
+		log.Error("failed to update outbox message", slog.String("error", err.Error()))
+	}
+}
+
+// publish sends event of msg to publishers which haven't published it yet and adds succeeded ones to msg.PublishedTo.
+func (r *Relay) publish(ctx context.Context, msg *model.OutboxMessage) error {
+	var event model.Event
+	if err := json.Unmarshal(msg.Payload, &event); err != nil {
+		return fmt.Errorf("%w: %w", errMalformedMessage, err)
+	}
+
+	var errs []error
+	for _, name := range r.names {
+		if slices.Contains(msg.PublishedTo, name) {
+			continue
+		}
+
+		if err := r.publishers[name].Publish(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("failed to publish event to %s: %w", name, err))
+			continue
+		}
+		msg.PublishedTo = append(msg.PublishedTo, name)
+	}
+
+	return errors.Join(errs...)
+}
+
+// backoff returns delay before the next attempt, doubling with every failed attempt.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= r.cfg.MaxBackoff {
+			return r.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
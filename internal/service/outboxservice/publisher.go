@@ -0,0 +1,73 @@
+package outboxservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+)
+
+// LogPublisher writes every event to the log.
+type LogPublisher struct {
+	log *slog.Logger
+}
+
+func NewLogPublisher(log *slog.Logger) *LogPublisher {
+	return &LogPublisher{
+		log: log.With(slog.String("publisher", "log")),
+	}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event model.Event) error {
+	p.log.Info("event published",
+		slog.String("event_id", event.ID),
+		slog.String("event_type", event.Type),
+		slog.Any("event", event),
+	)
+	return nil
+}
+
+// FilePublisher appends every event to a file as a JSON line.
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open events file: %w", err)
+	}
+
+	return &FilePublisher{
+		file: file,
+	}, nil
+}
+
+func (p *FilePublisher) Publish(_ context.Context, event model.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err = p.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	if err = p.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync events file: %w", err)
+	}
+
+	return nil
+}
+
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
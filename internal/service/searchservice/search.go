@@ -0,0 +1,73 @@
+package searchservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+)
+
+type savedSearchRepository interface {
+	InsertSavedSearch(ctx context.Context, search model.SavedSearch) (model.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id int) (model.SavedSearch, error)
+	GetSavedSearches(ctx context.Context) ([]model.SavedSearch, error)
+}
+
+type Service struct {
+	savedSearchRepository savedSearchRepository
+}
+
+func New(savedSearchRepository savedSearchRepository) *Service {
+	return &Service{
+		savedSearchRepository: savedSearchRepository,
+	}
+}
+
+type AddSearchInput struct {
+	Name   string
+	Filter string
+	Fields map[string][]string
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+func (s *Service) AddSearch(ctx context.Context, input AddSearchInput) (model.SavedSearch, error) {
+	search := model.SavedSearch{
+		Name:   input.Name,
+		Filter: input.Filter,
+		Fields: input.Fields,
+		Sort:   input.Sort,
+		Limit:  input.Limit,
+		Offset: input.Offset,
+	}
+
+	if search.Fields == nil {
+		search.Fields = map[string][]string{}
+	}
+
+	search, err := s.savedSearchRepository.InsertSavedSearch(ctx, search)
+	if err != nil {
+		return model.SavedSearch{}, fmt.Errorf("failed to add saved search: %w", err)
+	}
+
+	return search, nil
+}
+
+func (s *Service) GetSearch(ctx context.Context, id int) (model.SavedSearch, error) {
+	search, err := s.savedSearchRepository.GetSavedSearch(ctx, id)
+	if err != nil {
+		return model.SavedSearch{}, fmt.Errorf("failed to get saved search: %w", err)
+	}
+
+	return search, nil
+}
+
+func (s *Service) GetSearches(ctx context.Context) ([]model.SavedSearch, error) {
+	searches, err := s.savedSearchRepository.GetSavedSearches(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved searches: %w", err)
+	}
+
+	return searches, nil
+}
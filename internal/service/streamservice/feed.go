@@ -0,0 +1,166 @@
+package streamservice
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/config"
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+)
+
+const feedBatchSize = 100
+
+type outboxRepository interface {
+	GetLastMessageID(ctx context.Context) (int64, error)
+	GetMessagesAfter(ctx context.Context, id int64, skip []int64, limit int) ([]model.OutboxMessage, error)
+}
+
+// Feed publishes events of outbox messages to the stream. Every replica runs its own feed reading the outbox,
+// so stream subscribers get all events no matter which replica relays the outbox.
+//
+// Outbox ids are taken before transactions commit, so a message can become visible after messages with greater
+// ids. The feed keeps a cursor below which every message is handled and reads everything after it again, skipping
+// messages it has already published. A missing id is waited for GapTimeout before the cursor moves past it,
+// as it mostly belongs to a rolled back transaction.
+type Feed struct {
+	log              *slog.Logger
+	outboxRepository outboxRepository
+	stream           *Service
+	cfg              config.StreamConfig
+	wake             chan struct{}
+
+	started   bool
+	cursor    int64
+	published map[int64]struct{}
+	gaps      map[int64]time.Time
+}
+
+func NewFeed(log *slog.Logger, outboxRepository outboxRepository, stream *Service, cfg config.StreamConfig) *Feed {
+	return &Feed{
+		log:              log.With(slog.String("service", "stream_feed")),
+		outboxRepository: outboxRepository,
+		stream:           stream,
+		cfg:              cfg,
+		wake:             make(chan struct{}, 1),
+		published:        make(map[int64]struct{}),
+		gaps:             make(map[int64]time.Time),
+	}
+}
+
+// Notify makes the feed read the outbox without waiting for the next poll. It doesn't block.
+func (f *Feed) Notify() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run reads the outbox every poll interval and when notified until ctx is cancelled.
+func (f *Feed) Run(ctx context.Context) {
+	f.log.Info("stream feed started")
+
+	ticker := time.NewTicker(f.cfg.FeedPollInterval)
+	defer ticker.Stop()
+
+	for {
+		f.poll(ctx)
+
+		select {
+		case <-ctx.Done():
+			f.log.Info("stream feed stopped")
+			return
+		case <-ticker.C:
+		case <-f.wake:
+		}
+	}
+}
+
+// poll publishes messages after the cursor until there are none left. Only messages inserted after the feed
+// started are published. On error the cursor isn't moved, so the messages are read again by the next poll.
+func (f *Feed) poll(ctx context.Context) {
+	if !f.started {
+		id, err := f.outboxRepository.GetLastMessageID(ctx)
+		if err != nil {
+			f.log.Error("failed to get last outbox message id", slog.String("error", err.Error()))
+			return
+		}
+
+		f.cursor = id
+		f.started = true
+	}
+
+	for ctx.Err() == nil {
+		skip := make([]int64, 0, len(f.published))
+		for id := range f.published {
+			skip = append(skip, id)
+		}
+
+		messages, err := f.outboxRepository.GetMessagesAfter(ctx, f.cursor, skip, feedBatchSize)
+		if err != nil {
+			f.log.Error("failed to get outbox messages", slog.Int64("cursor", f.cursor), slog.String("error", err.Error()))
+			return
+		}
+
+		now := time.Now()
+		next := f.cursor + 1
+		for _, msg := range messages {
+			for ; next < msg.ID; next++ {
+				if _, ok := f.published[next]; ok {
+					continue
+				}
+				if _, ok := f.gaps[next]; !ok {
+					f.gaps[next] = now
+				}
+			}
+			next = msg.ID + 1
+
+			f.publish(msg)
+			f.published[msg.ID] = struct{}{}
+			delete(f.gaps, msg.ID)
+		}
+
+		f.advance(now)
+
+		if len(messages) < feedBatchSize {
+			return
+		}
+	}
+}
+
+// advance moves the cursor over published messages and gaps waited for longer than GapTimeout.
+func (f *Feed) advance(now time.Time) {
+	for {
+		id := f.cursor + 1
+
+		if _, ok := f.published[id]; ok {
+			delete(f.published, id)
+			f.cursor = id
+			continue
+		}
+
+		if noticed, ok := f.gaps[id]; ok && now.Sub(noticed) >= f.cfg.FeedGapTimeout {
+			f.log.Debug("outbox message didn't appear, skipped", slog.Int64("outbox_id", id))
+			delete(f.gaps, id)
+			f.cursor = id
+			continue
+		}
+
+		return
+	}
+}
+
+func (f *Feed) publish(msg model.OutboxMessage) {
+	if msg.AggregateType != model.AggregateCar {
+		return
+	}
+
+	var event model.Event
+	if err := json.Unmarshal(msg.Payload, &event); err != nil {
+		f.log.Error("failed to unmarshal outbox message", slog.Int64("outbox_id", msg.ID), slog.String("error", err.Error()))
+		return
+	}
+
+	f.stream.Publish(event)
+}
@@ -0,0 +1,104 @@
+package streamservice
+
+import (
+	"sync"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+)
+
+const subscriberBufferSize = 64
+
+// StreamEvent is an event with its position in the stream.
+type StreamEvent struct {
+	ID    uint64
+	Event model.Event
+}
+
+type subscriber struct {
+	events chan StreamEvent
+}
+
+// Service keeps the last published events in a bounded buffer and forwards new ones to subscribers.
+// Event ids are assigned in order of publishing starting from the startup time, so ids issued by other
+// replicas or before restart don't fall into the range of this stream.
+type Service struct {
+	mu          sync.Mutex
+	lastID      uint64
+	buffer      []StreamEvent
+	bufferSize  int
+	subscribers map[*subscriber]struct{}
+}
+
+func New(bufferSize int) *Service {
+	return &Service{
+		lastID:      uint64(time.Now().UnixNano()),
+		buffer:      make([]StreamEvent, 0, max(bufferSize, 0)),
+		bufferSize:  bufferSize,
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish appends car event to the buffer with the next id and sends it to subscribers. Subscribers that can't
+// keep up are disconnected, they are expected to reconnect with the last received event id.
+func (s *Service) Publish(event model.Event) {
+	if event.Car == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	streamEvent := StreamEvent{ID: s.lastID, Event: event}
+
+	if s.bufferSize > 0 {
+		if len(s.buffer) == s.bufferSize {
+			copy(s.buffer, s.buffer[1:])
+			s.buffer = s.buffer[:len(s.buffer)-1]
+		}
+		s.buffer = append(s.buffer, streamEvent)
+	}
+
+	for sub := range s.subscribers {
+		select {
+		case sub.events <- streamEvent:
+		default:
+			close(sub.events)
+			delete(s.subscribers, sub)
+		}
+	}
+}
+
+// Subscribe returns buffered events published after lastEventID and a channel of new events. All buffered events
+// are returned if lastEventID wasn't issued by this stream. The channel is closed when the subscriber falls behind.
+// unsubscribe must be called when the subscriber is done.
+func (s *Service) Subscribe(lastEventID uint64) (replay []StreamEvent, events <-chan StreamEvent, unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lastEventID > s.lastID {
+		lastEventID = 0
+	}
+
+	for _, event := range s.buffer {
+		if event.ID > lastEventID {
+			replay = append(replay, event)
+		}
+	}
+
+	sub := &subscriber{events: make(chan StreamEvent, subscriberBufferSize)}
+	s.subscribers[sub] = struct{}{}
+
+	unsubscribe = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if _, ok := s.subscribers[sub]; ok {
+			close(sub.events)
+			delete(s.subscribers, sub)
+		}
+	}
+
+	return replay, sub.events, unsubscribe
+}
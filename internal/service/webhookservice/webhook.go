@@ -0,0 +1,268 @@
+package webhookservice
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/internal/config"
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/webhook"
+)
+
+type webhookRepository interface {
+	InsertWebhook(ctx context.Context, webhook model.Webhook) (model.Webhook, error)
+	DeleteWebhook(ctx context.Context, id int) error
+	GetWebhook(ctx context.Context, id int) (model.Webhook, error)
+	GetWebhooks(ctx context.Context) ([]model.Webhook, error)
+	InsertDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery model.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, webhookID int) ([]model.WebhookDelivery, error)
+}
+
+type Service struct {
+	log               *slog.Logger
+	webhookRepository webhookRepository
+	client            http.Client
+	cfg               config.WebhooksConfig
+}
+
+func New(log *slog.Logger, webhookRepository webhookRepository, cfg config.WebhooksConfig) *Service {
+	return &Service{
+		log:               log.With(slog.String("service", "webhook")),
+		webhookRepository: webhookRepository,
+		client:            http.Client{Timeout: cfg.Timeout},
+		cfg:               cfg,
+	}
+}
+
+type AddWebhookInput struct {
+	URL    string
+	Secret string
+	Events []string
+}
+
+// AddWebhook creates webhook subscription. If secret is empty a random one is generated.
+func (s *Service) AddWebhook(ctx context.Context, input AddWebhookInput) (model.Webhook, error) {
+	secret := input.Secret
+	if secret == "" {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return model.Webhook{}, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(raw)
+	}
+
+	events := input.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	wh, err := s.webhookRepository.InsertWebhook(ctx, model.Webhook{
+		URL:    input.URL,
+		Secret: secret,
+		Events: events,
+	})
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("failed to add webhook: %w", err)
+	}
+
+	return wh, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, id int) error {
+	if err := s.webhookRepository.DeleteWebhook(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+// GetWebhooks returns all webhook subscriptions without their secrets.
+func (s *Service) GetWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	webhooks, err := s.webhookRepository.GetWebhooks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+func (s *Service) GetDeliveries(ctx context.Context, webhookID int) ([]model.WebhookDelivery, error) {
+	if _, err := s.webhookRepository.GetWebhook(ctx, webhookID); err != nil {
+		return nil, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+
+	deliveries, err := s.webhookRepository.GetDeliveries(ctx, webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Publish enqueues delivery of event to every webhook subscribed to its type.
+// Deliveries are sent by Run, so Publish doesn't wait for subscribers.
+func (s *Service) Publish(ctx context.Context, event model.Event) error {
+	log := s.log.With(slog.String("event_id", event.ID), slog.String("event_type", event.Type))
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	webhooks, err := s.webhookRepository.GetWebhooks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get webhooks: %w", err)
+	}
+
+	var errs []error
+	for _, wh := range webhooks {
+		if !wh.Accepts(event.Type) {
+			continue
+		}
+
+		err = s.webhookRepository.InsertDelivery(ctx, model.WebhookDelivery{
+			WebhookID: wh.ID,
+			EventID:   event.ID,
+			EventType: event.Type,
+			Payload:   payload,
+			Status:    model.DeliveryStatusPending,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to enqueue delivery to webhook %d: %w", wh.ID, err))
+			continue
+		}
+
+		log.Debug("delivery enqueued", slog.Int("webhook_id", wh.ID))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	s.log.Info("webhook delivery worker started")
+
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Info("webhook delivery worker stopped")
+			return
+		case <-ticker.C:
+			s.deliverDue(ctx)
+		}
+	}
+}
+
+func (s *Service) deliverDue(ctx context.Context) {
+	deliveries, err := s.webhookRepository.ClaimDueDeliveries(ctx, s.cfg.BatchSize, 2*s.cfg.Timeout)
+	if err != nil {
+		s.log.Error("failed to claim due deliveries", slog.String("error", err.Error()))
+		return
+	}
+
+	webhooks := make(map[int]model.Webhook)
+	for _, delivery := range deliveries {
+		wh, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			wh, err = s.webhookRepository.GetWebhook(ctx, delivery.WebhookID)
+			if err != nil {
+				s.log.Error("failed to get webhook", slog.Int("webhook_id", delivery.WebhookID), slog.String("error", err.Error()))
+				continue
+			}
+			webhooks[wh.ID] = wh
+		}
+
+		s.deliver(ctx, wh, delivery)
+	}
+}
+
+func (s *Service) deliver(ctx context.Context, wh model.Webhook, delivery model.WebhookDelivery) {
+	log := s.log.With(
+		slog.Int("webhook_id", wh.ID),
+		slog.Int("delivery_id", delivery.ID),
+		slog.String("event_type", delivery.EventType),
+	)
+
+	delivery.Attempts++
+	status, err := s.send(ctx, wh, delivery)
+	delivery.ResponseStatus = status
+
+	switch {
+	case err == nil:
+		delivery.Status = model.DeliveryStatusDelivered
+		delivery.LastError = ""
+		log.Info("event delivered", slog.Int("attempts", delivery.Attempts))
+	case delivery.Attempts >= s.cfg.MaxAttempts:
+		delivery.Status = model.DeliveryStatusDead
+		delivery.LastError = err.Error()
+		log.Error("delivery moved to dead letter", slog.Int("attempts", delivery.Attempts), slog.String("error", err.Error()))
+	default:
+		delivery.LastError = err.Error()
+		delivery.NextAttemptAt = time.Now().Add(s.backoff(delivery.Attempts))
+		log.Info("delivery failed, will retry",
+			slog.Int("attempts", delivery.Attempts),
+			slog.Time("next_attempt_at", delivery.NextAttemptAt),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	if err = s.webhookRepository.UpdateDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		log.Error("failed to update delivery", slog.String("error", err.Error()))
+	}
+}
+
+func (s *Service) send(ctx context.Context, wh model.Webhook, delivery model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("can't create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.HeaderEvent, delivery.EventType)
+	req.Header.Set(webhook.HeaderDelivery, strconv.Itoa(delivery.ID))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(wh.Secret, delivery.Payload))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("can't do request: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// backoff returns delay before the next attempt, doubling with every failed attempt.
+func (s *Service) backoff(attempts int) time.Duration {
+	delay := s.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= s.cfg.MaxBackoff {
+			return s.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
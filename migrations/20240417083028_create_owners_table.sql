@@ -12,5 +12,5 @@ CREATE TABLE IF NOT EXISTS owners
 
 -- +goose Down
 -- +goose StatementBegin
-DROP TABLE IF EXISTS owner;
+DROP TABLE IF EXISTS owners;
 -- +goose StatementEnd
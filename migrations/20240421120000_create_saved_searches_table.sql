@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS saved_searches
+(
+    id          SERIAL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL UNIQUE,
+    filter      TEXT         NOT NULL DEFAULT '',
+    sort        TEXT         NOT NULL DEFAULT '',
+    -- lists of values per field, so one field can be compared several times, e.g. year gt:2010 and lt:2020
+    fields      JSONB        NOT NULL DEFAULT '{}',
+    page_limit  INT          NOT NULL DEFAULT 0,
+    page_offset INT          NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS saved_searches;
+-- +goose StatementEnd
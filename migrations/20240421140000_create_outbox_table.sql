@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id              BIGSERIAL PRIMARY KEY,
+    aggregate_type  VARCHAR(32)  NOT NULL,
+    aggregate_id    VARCHAR(512) NOT NULL,
+    event_type      VARCHAR(255) NOT NULL,
+    payload         JSONB        NOT NULL,
+    status          VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    attempts        INT          NOT NULL DEFAULT 0,
+    last_error      TEXT         NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    published_to    TEXT[]       NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    published_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (aggregate_type, aggregate_id, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS outbox_published_idx ON outbox (published_at) WHERE status = 'published';
+
+CREATE OR REPLACE FUNCTION notify_outbox_messages() RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('outbox_messages', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_messages_notify
+    AFTER INSERT
+    ON outbox
+    FOR EACH STATEMENT
+EXECUTE FUNCTION notify_outbox_messages();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox;
+DROP FUNCTION IF EXISTS notify_outbox_messages();
+-- +goose StatementEnd
@@ -0,0 +1,54 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION notify_cars_changes() RETURNS TRIGGER AS
+$$
+DECLARE
+    reg_number VARCHAR;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        reg_number := OLD.registration_number;
+    ELSE
+        reg_number := NEW.registration_number;
+    END IF;
+
+    PERFORM pg_notify('cars_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'id', reg_number)::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_owners_changes() RETURNS TRIGGER AS
+$$
+DECLARE
+    owner_id VARCHAR;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        owner_id := OLD.name || ' ' || OLD.surname;
+    ELSE
+        owner_id := NEW.name || ' ' || NEW.surname;
+    END IF;
+
+    PERFORM pg_notify('owners_changes', json_build_object('table', TG_TABLE_NAME, 'op', TG_OP, 'id', owner_id)::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER cars_changes_notify
+    AFTER INSERT OR UPDATE OR DELETE
+    ON cars
+    FOR EACH ROW
+EXECUTE FUNCTION notify_cars_changes();
+
+CREATE TRIGGER owners_changes_notify
+    AFTER INSERT OR UPDATE OR DELETE
+    ON owners
+    FOR EACH ROW
+EXECUTE FUNCTION notify_owners_changes();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS owners_changes_notify ON owners;
+DROP TRIGGER IF EXISTS cars_changes_notify ON cars;
+DROP FUNCTION IF EXISTS notify_owners_changes();
+DROP FUNCTION IF EXISTS notify_cars_changes();
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS car_info_cache
+(
+    reg_number VARCHAR(512) PRIMARY KEY,
+    payload    BYTEA,
+    not_found  BOOLEAN     NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS car_info_cache_expires_at_idx ON car_info_cache (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS car_info_cache;
+-- +goose StatementEnd
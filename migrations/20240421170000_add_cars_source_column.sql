@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE cars ADD COLUMN IF NOT EXISTS source VARCHAR(64) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cars DROP COLUMN IF EXISTS source;
+-- +goose StatementEnd
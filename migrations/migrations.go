@@ -11,17 +11,97 @@ import (
 //go:embed *.sql
 var embedMigrations embed.FS
 
-func RunMigrations(db *sql.DB) error {
-
+func setup() error {
 	goose.SetBaseFS(embedMigrations)
 
 	if err := goose.SetDialect("postgres"); err != nil {
 		return fmt.Errorf("failed to set dialect: %w", err)
 	}
 
+	return nil
+}
+
+// RunMigrations applies all pending migrations.
+func RunMigrations(db *sql.DB) error {
+	if err := setup(); err != nil {
+		return err
+	}
+
 	if err := goose.Up(db, "."); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
 	return nil
 }
+
+// Down rolls back the last applied migration.
+func Down(db *sql.DB) error {
+	if err := setup(); err != nil {
+		return err
+	}
+
+	if err := goose.Down(db, "."); err != nil {
+		return fmt.Errorf("failed to roll back migration: %w", err)
+	}
+
+	return nil
+}
+
+// Redo rolls back the last applied migration and applies it again.
+func Redo(db *sql.DB) error {
+	if err := setup(); err != nil {
+		return err
+	}
+
+	if err := goose.Redo(db, "."); err != nil {
+		return fmt.Errorf("failed to redo migration: %w", err)
+	}
+
+	return nil
+}
+
+// Status logs every migration with the time it was applied at.
+func Status(db *sql.DB) error {
+	if err := setup(); err != nil {
+		return err
+	}
+
+	if err := goose.Status(db, "."); err != nil {
+		return fmt.Errorf("failed to get migrations status: %w", err)
+	}
+
+	return nil
+}
+
+// MigrateTo applies or rolls back migrations until the database is at version, 0 rolls back everything.
+func MigrateTo(db *sql.DB, version int64) error {
+	if err := setup(); err != nil {
+		return err
+	}
+
+	if version != 0 {
+		all, err := goose.CollectMigrations(".", 0, goose.MaxVersion)
+		if err != nil {
+			return fmt.Errorf("failed to collect migrations: %w", err)
+		}
+		if _, err = all.Current(version); err != nil {
+			return fmt.Errorf("unknown migration version %d: %w", version, err)
+		}
+	}
+
+	current, err := goose.GetDBVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to get database version: %w", err)
+	}
+
+	if version >= current {
+		err = goose.UpTo(db, ".", version)
+	} else {
+		err = goose.DownTo(db, ".", version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,319 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	LogicalAnd = "and"
+	LogicalOr  = "or"
+	LogicalNot = "not"
+)
+
+// Expr is a node of a parsed filter expression.
+type Expr interface {
+	// Pos returns 1-based position of the node in the source expression.
+	Pos() int
+	String() string
+}
+
+// BinaryExpr is a logical conjunction or disjunction of two expressions.
+type BinaryExpr struct {
+	Op    string
+	Left  Expr
+	Right Expr
+	pos   int
+}
+
+func (e *BinaryExpr) Pos() int { return e.pos }
+
+func (e *BinaryExpr) String() string {
+	return fmt.Sprintf("(%s %s %s)", e.Left, e.Op, e.Right)
+}
+
+// NotExpr is a logical negation of an expression.
+type NotExpr struct {
+	X   Expr
+	pos int
+}
+
+func (e *NotExpr) Pos() int { return e.pos }
+
+func (e *NotExpr) String() string {
+	return fmt.Sprintf("%s %s", LogicalNot, e.X)
+}
+
+// Comparison compares a field with a value using one of the filter operators.
+type Comparison struct {
+	Field    string
+	Op       string
+	Value    string
+	pos      int
+	opPos    int
+	valuePos int
+}
+
+func (e *Comparison) Pos() int { return e.pos }
+
+// OpPos returns 1-based position of the operator in the source expression.
+func (e *Comparison) OpPos() int { return e.opPos }
+
+// ValuePos returns 1-based position of the compared value in the source expression.
+func (e *Comparison) ValuePos() int { return e.valuePos }
+
+func (e *Comparison) String() string {
+	return fmt.Sprintf("%s %s %s", e.Field, e.Op, strconv.Quote(e.Value))
+}
+
+// ExpressionError describes an invalid filter expression and where the problem is.
+type ExpressionError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ExpressionError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// Walk calls fn for every node of expr in depth-first order and stops at the first error.
+func Walk(expr Expr, fn func(Expr) error) error {
+	if err := fn(expr); err != nil {
+		return err
+	}
+
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		if err := Walk(e.Left, fn); err != nil {
+			return err
+		}
+		return Walk(e.Right, fn)
+	case *NotExpr:
+		return Walk(e.X, fn)
+	}
+
+	return nil
+}
+
+// ParseExpression parses a boolean filter expression such as
+//
+//	(mark eq Lada or mark eq Kia) and year gt 2015
+//
+// Comparisons use the filter operators, values may be quoted with single or double quotes,
+// and comparisons can be combined with and, or, not and parentheses.
+func ParseExpression(s string) (Expr, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &ExpressionError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+	}
+
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenLParen:
+		return `"("`
+	case tokenRParen:
+		return `")"`
+	}
+	return strconv.Quote(t.value)
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, pos: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, pos: i + 1})
+			i++
+		case r == '\'' || r == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i == len(runes) {
+				return nil, &ExpressionError{Pos: start + 1, Msg: "unterminated string"}
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, value: sb.String(), pos: start + 1})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()'"`, runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: string(runes[start:i]), pos: start + 1})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes) + 1}), nil
+}
+
+type parser struct {
+	tokens []token
+	cur    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.cur]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.cur]
+	if tok.kind != tokenEOF {
+		p.cur++
+	}
+	return tok
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenWord && strings.EqualFold(tok.value, keyword)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword(LogicalOr) {
+		tok := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: LogicalOr, Left: left, Right: right, pos: tok.pos}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword(LogicalAnd) {
+		tok := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: LogicalAnd, Left: left, Right: right, pos: tok.pos}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.isKeyword(LogicalNot) {
+		tok := p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{X: x, pos: tok.pos}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.peek()
+
+	switch tok.kind {
+	case tokenLParen:
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &ExpressionError{Pos: closing.pos, Msg: fmt.Sprintf("expected \")\" but got %s", closing)}
+		}
+		return expr, nil
+	case tokenWord:
+		return p.parseComparison()
+	}
+
+	return nil, &ExpressionError{Pos: tok.pos, Msg: fmt.Sprintf("expected field name but got %s", tok)}
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	field := p.next()
+	if isLogicalKeyword(field.value) {
+		return nil, &ExpressionError{Pos: field.pos, Msg: fmt.Sprintf("expected field name but got %s", field)}
+	}
+
+	op := p.next()
+	if op.kind != tokenWord {
+		return nil, &ExpressionError{Pos: op.pos, Msg: fmt.Sprintf("expected operator but got %s", op)}
+	}
+	if err := validateOperator(strings.ToLower(op.value)); err != nil {
+		return nil, &ExpressionError{Pos: op.pos, Msg: fmt.Sprintf("unknown operator %s", op)}
+	}
+
+	value := p.next()
+	if value.kind != tokenWord && value.kind != tokenString {
+		return nil, &ExpressionError{Pos: value.pos, Msg: fmt.Sprintf("expected value but got %s", value)}
+	}
+	if value.kind == tokenWord && isLogicalKeyword(value.value) {
+		return nil, &ExpressionError{Pos: value.pos, Msg: fmt.Sprintf("expected value but got %s", value)}
+	}
+
+	return &Comparison{
+		Field:    field.value,
+		Op:       strings.ToLower(op.value),
+		Value:    value.value,
+		pos:      field.pos,
+		opPos:    op.pos,
+		valuePos: value.pos,
+	}, nil
+}
+
+func isLogicalKeyword(s string) bool {
+	return strings.EqualFold(s, LogicalAnd) || strings.EqualFold(s, LogicalOr) || strings.EqualFold(s, LogicalNot)
+}
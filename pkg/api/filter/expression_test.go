@@ -0,0 +1,243 @@
+package filter_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+)
+
+func TestParseExpression(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{
+			name: "comparison",
+			expr: "year gt 2015",
+			want: `year gt "2015"`,
+		},
+		{
+			name: "and binds tighter than or",
+			expr: "a eq 1 or b eq 2 and c eq 3",
+			want: `(a eq "1" or (b eq "2" and c eq "3"))`,
+		},
+		{
+			name: "and binds tighter than or on the left",
+			expr: "a eq 1 and b eq 2 or c eq 3",
+			want: `((a eq "1" and b eq "2") or c eq "3")`,
+		},
+		{
+			name: "not binds tighter than and",
+			expr: "not a eq 1 and b eq 2",
+			want: `(not a eq "1" and b eq "2")`,
+		},
+		{
+			name: "double negation",
+			expr: "not not a eq 1",
+			want: `not not a eq "1"`,
+		},
+		{
+			name: "parentheses override precedence",
+			expr: "(a eq 1 or b eq 2) and c eq 3",
+			want: `((a eq "1" or b eq "2") and c eq "3")`,
+		},
+		{
+			name: "not of group",
+			expr: "not (a eq 1 or b eq 2)",
+			want: `not (a eq "1" or b eq "2")`,
+		},
+		{
+			name: "or is left associative",
+			expr: "a eq 1 or b eq 2 or c eq 3",
+			want: `((a eq "1" or b eq "2") or c eq "3")`,
+		},
+		{
+			name: "keywords and operators are case insensitive",
+			expr: "a EQ 1 AND Not b NEQ 2",
+			want: `(a eq "1" and not b neq "2")`,
+		},
+		{
+			name: "quoted values",
+			expr: `mark eq 'Land Rover' or model eq "and"`,
+			want: `(mark eq "Land Rover" or model eq "and")`,
+		},
+		{
+			name: "empty quoted value",
+			expr: `model eq ''`,
+			want: `model eq ""`,
+		},
+		{
+			name: "no spaces around parentheses",
+			expr: "(a eq 1)and(b eq 2)",
+			want: `(a eq "1" and b eq "2")`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := filter.ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression(%q): %v", tt.expr, err)
+			}
+
+			if got := expr.String(); got != tt.want {
+				t.Errorf("ParseExpression(%q) = %s, want %s", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExpressionPositions(t *testing.T) {
+	expr, err := filter.ParseExpression("  year gt 2015 and  mark eq 'Kia'")
+	if err != nil {
+		t.Fatalf("ParseExpression: %v", err)
+	}
+
+	and, ok := expr.(*filter.BinaryExpr)
+	if !ok {
+		t.Fatalf("expr = %T, want *filter.BinaryExpr", expr)
+	}
+	if and.Pos() != 16 {
+		t.Errorf("and position = %d, want 16", and.Pos())
+	}
+
+	tests := []struct {
+		name                 string
+		expr                 filter.Expr
+		pos, opPos, valuePos int
+	}{
+		{name: "left", expr: and.Left, pos: 3, opPos: 8, valuePos: 11},
+		{name: "right", expr: and.Right, pos: 21, opPos: 26, valuePos: 29},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmp, ok := tt.expr.(*filter.Comparison)
+			if !ok {
+				t.Fatalf("expr = %T, want *filter.Comparison", tt.expr)
+			}
+
+			if cmp.Pos() != tt.pos || cmp.OpPos() != tt.opPos || cmp.ValuePos() != tt.valuePos {
+				t.Errorf("positions = %d, %d, %d, want %d, %d, %d",
+					cmp.Pos(), cmp.OpPos(), cmp.ValuePos(), tt.pos, tt.opPos, tt.valuePos)
+			}
+		})
+	}
+}
+
+func TestParseExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		wantPos int
+		wantMsg string
+	}{
+		{
+			name:    "empty",
+			expr:    "",
+			wantPos: 1,
+			wantMsg: "expected field name but got end of expression",
+		},
+		{
+			name:    "missing operator",
+			expr:    "mark",
+			wantPos: 5,
+			wantMsg: "expected operator but got end of expression",
+		},
+		{
+			name:    "missing value",
+			expr:    "mark eq",
+			wantPos: 8,
+			wantMsg: "expected value but got end of expression",
+		},
+		{
+			name:    "unknown operator",
+			expr:    "mark like Lada",
+			wantPos: 6,
+			wantMsg: `unknown operator "like"`,
+		},
+		{
+			name:    "parenthesis instead of operator",
+			expr:    "mark ( Lada",
+			wantPos: 6,
+			wantMsg: `expected operator but got "("`,
+		},
+		{
+			name:    "keyword instead of field",
+			expr:    "and eq 1",
+			wantPos: 1,
+			wantMsg: `expected field name but got "and"`,
+		},
+		{
+			name:    "keyword instead of value",
+			expr:    "mark eq or",
+			wantPos: 9,
+			wantMsg: `expected value but got "or"`,
+		},
+		{
+			name:    "dangling and",
+			expr:    "mark eq Lada and",
+			wantPos: 17,
+			wantMsg: "expected field name but got end of expression",
+		},
+		{
+			name:    "dangling not",
+			expr:    "not",
+			wantPos: 4,
+			wantMsg: "expected field name but got end of expression",
+		},
+		{
+			name:    "unterminated string",
+			expr:    "mark eq 'Lada",
+			wantPos: 9,
+			wantMsg: "unterminated string",
+		},
+		{
+			name:    "unclosed parenthesis",
+			expr:    "(mark eq Lada",
+			wantPos: 14,
+			wantMsg: `expected ")" but got end of expression`,
+		},
+		{
+			name:    "unopened parenthesis",
+			expr:    "mark eq Lada)",
+			wantPos: 13,
+			wantMsg: `unexpected ")"`,
+		},
+		{
+			name:    "empty parentheses",
+			expr:    "()",
+			wantPos: 2,
+			wantMsg: `expected field name but got ")"`,
+		},
+		{
+			name:    "missing logical operator",
+			expr:    "mark eq Lada model eq Vesta",
+			wantPos: 14,
+			wantMsg: `unexpected "model"`,
+		},
+		{
+			name:    "positions count runes",
+			expr:    "марка eq Лада or",
+			wantPos: 17,
+			wantMsg: "expected field name but got end of expression",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := filter.ParseExpression(tt.expr)
+
+			var exprErr *filter.ExpressionError
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("ParseExpression(%q) error = %v, want *filter.ExpressionError", tt.expr, err)
+			}
+
+			if exprErr.Pos != tt.wantPos || exprErr.Msg != tt.wantMsg {
+				t.Errorf("ParseExpression(%q) error = %q at %d, want %q at %d", tt.expr, exprErr.Msg, exprErr.Pos, tt.wantMsg, tt.wantPos)
+			}
+		})
+	}
+}
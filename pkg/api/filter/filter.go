@@ -3,8 +3,10 @@ package filter
 import "fmt"
 
 const (
-	DataTypeStr = "string"
-	DataTypeInt = "int"
+	DataTypeStr  = "string"
+	DataTypeInt  = "int"
+	DataTypeDate = "date"
+	DataTypeBool = "bool"
 
 	OperatorEq            = "eq"
 	OperatorNotEq         = "neq"
@@ -15,7 +17,8 @@ const (
 )
 
 type options struct {
-	fields []Field
+	fields     []Field
+	expression Expr
 }
 
 func NewOptions() Options {
@@ -32,6 +35,8 @@ type Field struct {
 type Options interface {
 	AddField(name, op, value, type_ string) error
 	Fields() []Field
+	SetExpression(expr Expr)
+	Expression() Expr
 }
 
 func (o *options) AddField(name, op, value, type_ string) error {
@@ -53,6 +58,14 @@ func (o *options) Fields() []Field {
 	return o.fields
 }
 
+func (o *options) SetExpression(expr Expr) {
+	o.expression = expr
+}
+
+func (o *options) Expression() Expr {
+	return o.expression
+}
+
 func ParseOperator(op string) (string, error) {
 	switch op {
 	case OperatorEq:
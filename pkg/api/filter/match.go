@@ -0,0 +1,108 @@
+package filter
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Match reports whether model satisfies filterOptions. It is the in-memory counterpart of
+// the SQL built from the same options, so model must be of the type the schema was built from.
+func (s *Schema) Match(filterOptions Options, model interface{}) (bool, error) {
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	for _, field := range filterOptions.Fields() {
+		ok, err := s.matchComparison(v, field.Name, field.Op, field.Value)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+
+	if expr := filterOptions.Expression(); expr != nil {
+		return s.matchExpression(v, expr)
+	}
+
+	return true, nil
+}
+
+func (s *Schema) matchExpression(v reflect.Value, expr Expr) (bool, error) {
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		left, err := s.matchExpression(v, e.Left)
+		if err != nil {
+			return false, err
+		}
+
+		switch e.Op {
+		case LogicalAnd:
+			if !left {
+				return false, nil
+			}
+		case LogicalOr:
+			if left {
+				return true, nil
+			}
+		default:
+			return false, fmt.Errorf("unknown logical operator %q", e.Op)
+		}
+
+		return s.matchExpression(v, e.Right)
+	case *NotExpr:
+		ok, err := s.matchExpression(v, e.X)
+		return !ok, err
+	case *Comparison:
+		return s.matchComparison(v, e.Field, e.Op, e.Value)
+	}
+
+	return false, fmt.Errorf("unknown expression %T", expr)
+}
+
+func (s *Schema) matchComparison(v reflect.Value, name, op, value string) (bool, error) {
+	field, err := s.Validate(name, op, value)
+	if err != nil {
+		return false, err
+	}
+
+	expected, err := field.ParseValue(value)
+	if err != nil {
+		return false, err
+	}
+
+	actual := v.FieldByIndex(field.index)
+
+	var c int
+	switch field.Type {
+	case DataTypeInt:
+		c = cmp.Compare(actual.Int(), int64(expected.(int)))
+	case DataTypeStr:
+		c = strings.Compare(actual.String(), expected.(string))
+	case DataTypeBool:
+		if actual.Bool() != expected.(bool) {
+			c = 1
+		}
+	case DataTypeDate:
+		c = actual.Interface().(time.Time).Compare(expected.(time.Time))
+	}
+
+	switch op {
+	case OperatorEq:
+		return c == 0, nil
+	case OperatorNotEq:
+		return c != 0, nil
+	case OperatorLowerThan:
+		return c < 0, nil
+	case OperatorLowerThanEq:
+		return c <= 0, nil
+	case OperatorGreaterThan:
+		return c > 0, nil
+	case OperatorGreaterThanEq:
+		return c >= 0, nil
+	}
+
+	return false, fmt.Errorf("%w %q for field %q", ErrUnsupportedOperator, op, name)
+}
@@ -0,0 +1,302 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/tag"
+)
+
+const (
+	// DateLayout is the layout of DataTypeDate values.
+	DateLayout = time.DateOnly
+
+	filterTagName = "filter"
+)
+
+var (
+	ErrUnknownField        = errors.New("unknown field")
+	ErrUnsupportedOperator = errors.New("unsupported operator")
+	ErrInvalidValue        = errors.New("invalid value")
+)
+
+var defaultOperators = map[string][]string{
+	DataTypeStr:  {OperatorEq, OperatorNotEq},
+	DataTypeBool: {OperatorEq, OperatorNotEq},
+	DataTypeInt: {
+		OperatorEq, OperatorNotEq,
+		OperatorLowerThan, OperatorLowerThanEq,
+		OperatorGreaterThan, OperatorGreaterThanEq,
+	},
+	DataTypeDate: {
+		OperatorEq, OperatorNotEq,
+		OperatorLowerThan, OperatorLowerThanEq,
+		OperatorGreaterThan, OperatorGreaterThanEq,
+	},
+}
+
+// FieldSchema describes a single filterable field of a model.
+type FieldSchema struct {
+	// Name is the field name used by API clients, taken from the json tag.
+	Name string
+	// Column is the database column, taken from the db tag.
+	Column    string
+	Type      string
+	Operators []string
+
+	index []int
+}
+
+// Allows reports whether op can be used with the field.
+func (f FieldSchema) Allows(op string) bool {
+	return slices.Contains(f.Operators, op)
+}
+
+// ParseValue converts value into the Go type matching the field type.
+func (f FieldSchema) ParseValue(value string) (interface{}, error) {
+	switch f.Type {
+	case DataTypeInt:
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %q expects integer value", ErrInvalidValue, f.Name)
+		}
+		return v, nil
+	case DataTypeBool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %q expects boolean value", ErrInvalidValue, f.Name)
+		}
+		return v, nil
+	case DataTypeDate:
+		v, err := time.Parse(DateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: field %q expects date value in %s format", ErrInvalidValue, f.Name, DateLayout)
+		}
+		return v, nil
+	}
+
+	return value, nil
+}
+
+// Schema is a set of filterable fields of a model.
+type Schema struct {
+	fields []FieldSchema
+	byName map[string]FieldSchema
+}
+
+var registry sync.Map
+
+// SchemaOf returns the schema of model. Schemas are built once per type and cached.
+func SchemaOf(model interface{}) (*Schema, error) {
+	t := reflect.TypeOf(model)
+	if cached, ok := registry.Load(t); ok {
+		return cached.(*Schema), nil
+	}
+
+	schema, err := NewSchema(model)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, _ := registry.LoadOrStore(t, schema)
+	return cached.(*Schema), nil
+}
+
+// MustSchemaOf is like SchemaOf but panics if model has invalid filter tags.
+func MustSchemaOf(model interface{}) *Schema {
+	schema, err := SchemaOf(model)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// NewSchema builds the schema of model from its struct tags. Only fields with a filter tag are filterable.
+// The filter tag lists the field type followed by allowed operators, e.g. `filter:"int,eq,gt,lt"`.
+// If no operators are listed, all operators supported by the type are allowed.
+func NewSchema(model interface{}) (*Schema, error) {
+	t := reflect.TypeOf(model)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can't build filter schema: %v is not a struct", t)
+	}
+
+	schema := &Schema{byName: make(map[string]FieldSchema)}
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+
+		filterTag, ok := structField.Tag.Lookup(filterTagName)
+		if !ok || filterTag == "-" {
+			continue
+		}
+
+		field, err := parseFilterTag(filterTag)
+		if err != nil {
+			return nil, fmt.Errorf("can't build filter schema for %s.%s: %w", t.Name(), structField.Name, err)
+		}
+
+		field.Name = tag.ParseJsonTag(structField.Tag.Get("json"))
+		if field.Name == "" {
+			field.Name = structField.Name
+		}
+
+		if err = checkGoType(field.Type, structField.Type); err != nil {
+			return nil, fmt.Errorf("can't build filter schema for %s.%s: %w", t.Name(), structField.Name, err)
+		}
+		field.index = structField.Index
+
+		field.Column = structField.Tag.Get("db")
+		if field.Column == "" {
+			return nil, fmt.Errorf("can't build filter schema for %s.%s: db tag is required", t.Name(), structField.Name)
+		}
+
+		if _, exists := schema.byName[field.Name]; exists {
+			return nil, fmt.Errorf("can't build filter schema for %s: duplicate field %q", t.Name(), field.Name)
+		}
+
+		schema.fields = append(schema.fields, field)
+		schema.byName[field.Name] = field
+	}
+
+	return schema, nil
+}
+
+func parseFilterTag(filterTag string) (FieldSchema, error) {
+	parts := strings.Split(filterTag, ",")
+
+	type_ := strings.TrimSpace(parts[0])
+	allowed, ok := defaultOperators[type_]
+	if !ok {
+		return FieldSchema{}, fmt.Errorf("unknown filter type %q", type_)
+	}
+
+	var operators []string
+	for _, op := range parts[1:] {
+		op = strings.TrimSpace(op)
+		if !slices.Contains(allowed, op) {
+			return FieldSchema{}, fmt.Errorf("operator %q is not supported for type %q", op, type_)
+		}
+		operators = append(operators, op)
+	}
+
+	if len(operators) == 0 {
+		operators = allowed
+	}
+
+	return FieldSchema{Type: type_, Operators: operators}, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func checkGoType(type_ string, goType reflect.Type) error {
+	var ok bool
+	switch type_ {
+	case DataTypeStr:
+		ok = goType.Kind() == reflect.String
+	case DataTypeBool:
+		ok = goType.Kind() == reflect.Bool
+	case DataTypeInt:
+		switch goType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			ok = true
+		}
+	case DataTypeDate:
+		ok = goType == timeType
+	}
+
+	if !ok {
+		return fmt.Errorf("filter type %q doesn't match go type %s", type_, goType)
+	}
+	return nil
+}
+
+// Fields returns filterable fields in declaration order.
+func (s *Schema) Fields() []FieldSchema {
+	return s.fields
+}
+
+// Field returns the field with the given API name.
+func (s *Schema) Field(name string) (FieldSchema, bool) {
+	field, ok := s.byName[name]
+	return field, ok
+}
+
+// Validate checks that name is a filterable field, op is allowed for it and value has the field type.
+func (s *Schema) Validate(name, op, value string) (FieldSchema, error) {
+	field, ok := s.Field(name)
+	if !ok {
+		return FieldSchema{}, fmt.Errorf("%w %q", ErrUnknownField, name)
+	}
+
+	if !field.Allows(op) {
+		return FieldSchema{}, fmt.Errorf("%w %q for field %q", ErrUnsupportedOperator, op, name)
+	}
+
+	if _, err := field.ParseValue(value); err != nil {
+		return FieldSchema{}, err
+	}
+
+	return field, nil
+}
+
+// ValidateExpression checks every comparison in expr against the schema.
+// Returned errors are *ExpressionError pointing to the offending part of the expression.
+func (s *Schema) ValidateExpression(expr Expr) error {
+	return Walk(expr, func(expr Expr) error {
+		cmp, ok := expr.(*Comparison)
+		if !ok {
+			return nil
+		}
+
+		field, ok := s.Field(cmp.Field)
+		if !ok {
+			return &ExpressionError{Pos: cmp.Pos(), Msg: fmt.Sprintf("%s %q", ErrUnknownField, cmp.Field)}
+		}
+
+		if !field.Allows(cmp.Op) {
+			return &ExpressionError{Pos: cmp.OpPos(), Msg: fmt.Sprintf("%s %q for field %q", ErrUnsupportedOperator, cmp.Op, cmp.Field)}
+		}
+
+		if _, err := field.ParseValue(cmp.Value); err != nil {
+			return &ExpressionError{Pos: cmp.ValuePos(), Msg: err.Error()}
+		}
+
+		return nil
+	})
+}
+
+// OptionsFromQuery builds filter options from query parameters named after schema fields.
+// A parameter value is either a plain value compared for equality or "operator:value", e.g. year=gt:2010.
+func (s *Schema) OptionsFromQuery(query url.Values) (Options, error) {
+	filterOptions := NewOptions()
+
+	for _, field := range s.fields {
+		for _, strValue := range query[field.Name] {
+			if strValue == "" {
+				continue
+			}
+
+			operator := OperatorEq
+			if op, value, found := strings.Cut(strValue, ":"); found && validateOperator(op) == nil {
+				operator = op
+				strValue = value
+			}
+
+			if _, err := s.Validate(field.Name, operator, strValue); err != nil {
+				return nil, fmt.Errorf("failed to parse filter: %w", err)
+			}
+
+			if err := filterOptions.AddField(field.Name, operator, strValue, field.Type); err != nil {
+				return nil, fmt.Errorf("failed to parse filter: %w", err)
+			}
+		}
+	}
+
+	return filterOptions, nil
+}
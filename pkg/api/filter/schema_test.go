@@ -0,0 +1,510 @@
+package filter_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+)
+
+type testModel struct {
+	RegNum    string    `db:"reg_number" json:"regNum,omitempty" filter:"string,eq"`
+	Mark      string    `db:"mark" json:"mark" filter:"string"`
+	Year      int       `db:"year" json:"year" filter:"int"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt" filter:"date,gt,lt"`
+	Electric  bool      `db:"electric" json:"electric" filter:"bool"`
+	Color     string    `db:"color" json:"color"`
+	Model     string    `db:"model" json:"model" filter:"-"`
+}
+
+func TestNewSchema(t *testing.T) {
+	schema, err := filter.NewSchema(testModel{})
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+
+	want := []filter.FieldSchema{
+		{Name: "regNum", Column: "reg_number", Type: filter.DataTypeStr, Operators: []string{"eq"}},
+		{Name: "mark", Column: "mark", Type: filter.DataTypeStr, Operators: []string{"eq", "neq"}},
+		{Name: "year", Column: "year", Type: filter.DataTypeInt, Operators: []string{"eq", "neq", "lt", "lte", "gt", "gte"}},
+		{Name: "createdAt", Column: "created_at", Type: filter.DataTypeDate, Operators: []string{"gt", "lt"}},
+		{Name: "electric", Column: "electric", Type: filter.DataTypeBool, Operators: []string{"eq", "neq"}},
+	}
+
+	fields := schema.Fields()
+	if len(fields) != len(want) {
+		t.Fatalf("got %d fields, want %d", len(fields), len(want))
+	}
+
+	for i, field := range fields {
+		if field.Name != want[i].Name || field.Column != want[i].Column || field.Type != want[i].Type ||
+			!slices.Equal(field.Operators, want[i].Operators) {
+			t.Errorf("field %d = %+v, want %+v", i, field, want[i])
+		}
+	}
+}
+
+func TestNewSchemaErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		model interface{}
+	}{
+		{
+			name:  "not a struct",
+			model: &testModel{},
+		},
+		{
+			name:  "nil",
+			model: nil,
+		},
+		{
+			name: "unknown type",
+			model: struct {
+				Mark string `db:"mark" json:"mark" filter:"text"`
+			}{},
+		},
+		{
+			name: "empty tag",
+			model: struct {
+				Mark string `db:"mark" json:"mark" filter:""`
+			}{},
+		},
+		{
+			name: "unknown operator",
+			model: struct {
+				Mark string `db:"mark" json:"mark" filter:"string,like"`
+			}{},
+		},
+		{
+			name: "operator unsupported for type",
+			model: struct {
+				Mark string `db:"mark" json:"mark" filter:"string,eq,gt"`
+			}{},
+		},
+		{
+			name: "type doesn't match go type",
+			model: struct {
+				Year string `db:"year" json:"year" filter:"int"`
+			}{},
+		},
+		{
+			name: "date isn't time",
+			model: struct {
+				CreatedAt string `db:"created_at" json:"createdAt" filter:"date"`
+			}{},
+		},
+		{
+			name: "missing db tag",
+			model: struct {
+				Mark string `json:"mark" filter:"string"`
+			}{},
+		},
+		{
+			name: "duplicate field",
+			model: struct {
+				Mark string `db:"mark" json:"mark" filter:"string"`
+				mark string `db:"brand" filter:"string"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := filter.NewSchema(tt.model); err == nil {
+				t.Error("NewSchema() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestSchemaOfCaches(t *testing.T) {
+	first, err := filter.SchemaOf(testModel{})
+	if err != nil {
+		t.Fatalf("SchemaOf: %v", err)
+	}
+
+	second, err := filter.SchemaOf(testModel{Mark: "Lada"})
+	if err != nil {
+		t.Fatalf("SchemaOf: %v", err)
+	}
+
+	if first != second {
+		t.Error("SchemaOf returned different schemas for the same type")
+	}
+
+	type otherModel struct {
+		Mark string `db:"mark" json:"mark" filter:"string"`
+	}
+	other, err := filter.SchemaOf(otherModel{})
+	if err != nil {
+		t.Fatalf("SchemaOf: %v", err)
+	}
+
+	if other == first {
+		t.Error("SchemaOf returned the same schema for different types")
+	}
+}
+
+func TestMustSchemaOfPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustSchemaOf didn't panic on invalid filter tag")
+		}
+	}()
+
+	filter.MustSchemaOf(struct {
+		Mark string `db:"mark" json:"mark" filter:"string,gt"`
+	}{})
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema := filter.MustSchemaOf(testModel{})
+
+	tests := []struct {
+		name      string
+		field     string
+		op        string
+		value     string
+		wantValue interface{}
+		wantErr   error
+	}{
+		{
+			name:      "string",
+			field:     "mark",
+			op:        "neq",
+			value:     "Lada",
+			wantValue: "Lada",
+		},
+		{
+			name:      "int",
+			field:     "year",
+			op:        "gte",
+			value:     "2015",
+			wantValue: 2015,
+		},
+		{
+			name:      "negative int",
+			field:     "year",
+			op:        "eq",
+			value:     "-1",
+			wantValue: -1,
+		},
+		{
+			name:    "invalid int",
+			field:   "year",
+			op:      "eq",
+			value:   "2015.5",
+			wantErr: filter.ErrInvalidValue,
+		},
+		{
+			name:      "date",
+			field:     "createdAt",
+			op:        "gt",
+			value:     "2024-04-21",
+			wantValue: time.Date(2024, time.April, 21, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid date",
+			field:   "createdAt",
+			op:      "gt",
+			value:   "21.04.2024",
+			wantErr: filter.ErrInvalidValue,
+		},
+		{
+			name:      "bool",
+			field:     "electric",
+			op:        "eq",
+			value:     "true",
+			wantValue: true,
+		},
+		{
+			name:      "bool as number",
+			field:     "electric",
+			op:        "neq",
+			value:     "0",
+			wantValue: false,
+		},
+		{
+			name:    "invalid bool",
+			field:   "electric",
+			op:      "eq",
+			value:   "yes",
+			wantErr: filter.ErrInvalidValue,
+		},
+		{
+			name:    "unknown field",
+			field:   "price",
+			op:      "eq",
+			value:   "1",
+			wantErr: filter.ErrUnknownField,
+		},
+		{
+			name:    "field without filter tag",
+			field:   "color",
+			op:      "eq",
+			value:   "red",
+			wantErr: filter.ErrUnknownField,
+		},
+		{
+			name:    "field excluded from filtering",
+			field:   "model",
+			op:      "eq",
+			value:   "Vesta",
+			wantErr: filter.ErrUnknownField,
+		},
+		{
+			name:    "field is looked up by json name",
+			field:   "reg_number",
+			op:      "eq",
+			value:   "X123XX150",
+			wantErr: filter.ErrUnknownField,
+		},
+		{
+			name:    "operator unsupported for type",
+			field:   "mark",
+			op:      "gt",
+			value:   "Lada",
+			wantErr: filter.ErrUnsupportedOperator,
+		},
+		{
+			name:    "operator not listed in tag",
+			field:   "createdAt",
+			op:      "eq",
+			value:   "2024-04-21",
+			wantErr: filter.ErrUnsupportedOperator,
+		},
+		{
+			name:    "unknown operator",
+			field:   "year",
+			op:      "like",
+			value:   "2015",
+			wantErr: filter.ErrUnsupportedOperator,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, err := schema.Validate(tt.field, tt.op, tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Validate(%q, %q, %q) error = %v, want %v", tt.field, tt.op, tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			value, err := field.ParseValue(tt.value)
+			if err != nil {
+				t.Fatalf("ParseValue(%q): %v", tt.value, err)
+			}
+			if !reflect.DeepEqual(value, tt.wantValue) {
+				t.Errorf("ParseValue(%q) = %#v, want %#v", tt.value, value, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestSchemaOptionsFromQuery(t *testing.T) {
+	schema := filter.MustSchemaOf(testModel{})
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []filter.Field
+		wantErr error
+	}{
+		{
+			name:  "plain value is compared for equality",
+			query: "mark=Lada",
+			want:  []filter.Field{{Name: "mark", Op: "eq", Value: "Lada", Type: filter.DataTypeStr}},
+		},
+		{
+			name:  "several values per field",
+			query: "year=gt:2010&year=lt:2020",
+			want: []filter.Field{
+				{Name: "year", Op: "gt", Value: "2010", Type: filter.DataTypeInt},
+				{Name: "year", Op: "lt", Value: "2020", Type: filter.DataTypeInt},
+			},
+		},
+		{
+			name:  "fields in schema order",
+			query: "electric=false&mark=Kia",
+			want: []filter.Field{
+				{Name: "mark", Op: "eq", Value: "Kia", Type: filter.DataTypeStr},
+				{Name: "electric", Op: "eq", Value: "false", Type: filter.DataTypeBool},
+			},
+		},
+		{
+			name:  "colon in value",
+			query: "mark=" + url.QueryEscape("Lada:Niva"),
+			want:  []filter.Field{{Name: "mark", Op: "eq", Value: "Lada:Niva", Type: filter.DataTypeStr}},
+		},
+		{
+			name:  "unknown and empty parameters are ignored",
+			query: "limit=10&price=gt:1&mark=",
+		},
+		{
+			name:    "unsupported operator",
+			query:   "mark=gt:Lada",
+			wantErr: filter.ErrUnsupportedOperator,
+		},
+		{
+			name:    "unknown operator is part of value",
+			query:   "year=like:2010",
+			wantErr: filter.ErrInvalidValue,
+		},
+		{
+			name:    "invalid date",
+			query:   "createdAt=gt:yesterday",
+			wantErr: filter.ErrInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery: %v", err)
+			}
+
+			options, err := schema.OptionsFromQuery(query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("OptionsFromQuery(%q) error = %v, want %v", tt.query, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got := options.Fields(); !slices.Equal(got, tt.want) {
+				t.Errorf("OptionsFromQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaParseSort(t *testing.T) {
+	schema := filter.MustSchemaOf(testModel{})
+
+	tests := []struct {
+		name    string
+		sort    string
+		want    []filter.SortField
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "ascending",
+			sort: "year",
+			want: []filter.SortField{{Name: "year"}},
+		},
+		{
+			name: "several fields",
+			sort: "-year, mark",
+			want: []filter.SortField{{Name: "year", Desc: true}, {Name: "mark"}},
+		},
+		{
+			name:    "unknown field",
+			sort:    "-price",
+			wantErr: true,
+		},
+		{
+			name:    "column name",
+			sort:    "reg_number",
+			wantErr: true,
+		},
+		{
+			name:    "sql",
+			sort:    "year; DROP TABLE cars",
+			wantErr: true,
+		},
+		{
+			name:    "empty field",
+			sort:    "year,",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schema.ParseSort(tt.sort)
+			if tt.wantErr {
+				if !errors.Is(err, filter.ErrUnknownField) {
+					t.Errorf("ParseSort(%q) error = %v, want %v", tt.sort, err, filter.ErrUnknownField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSort(%q): %v", tt.sort, err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseSort(%q) = %v, want %v", tt.sort, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaValidateExpression(t *testing.T) {
+	schema := filter.MustSchemaOf(testModel{})
+
+	tests := []struct {
+		name    string
+		expr    string
+		wantPos int
+		wantErr string
+	}{
+		{
+			name: "valid",
+			expr: "mark eq Lada and year gte 2010",
+		},
+		{
+			name:    "unknown field",
+			expr:    "mark eq Lada or price lt 100",
+			wantPos: 17,
+			wantErr: `unknown field "price"`,
+		},
+		{
+			name:    "unsupported operator",
+			expr:    "not mark gt Lada",
+			wantPos: 10,
+			wantErr: `unsupported operator "gt" for field "mark"`,
+		},
+		{
+			name:    "invalid value",
+			expr:    "(year eq old)",
+			wantPos: 10,
+			wantErr: `invalid value: field "year" expects integer value`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := filter.ParseExpression(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseExpression(%q): %v", tt.expr, err)
+			}
+
+			err = schema.ValidateExpression(expr)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateExpression(%q) = %v, want nil", tt.expr, err)
+				}
+				return
+			}
+
+			var exprErr *filter.ExpressionError
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("ValidateExpression(%q) = %v, want *filter.ExpressionError", tt.expr, err)
+			}
+
+			if exprErr.Pos != tt.wantPos || exprErr.Msg != tt.wantErr {
+				t.Errorf("ValidateExpression(%q) = %q at %d, want %q at %d", tt.expr, exprErr.Msg, exprErr.Pos, tt.wantErr, tt.wantPos)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortField is a field results are ordered by.
+type SortField struct {
+	Name string
+	Desc bool
+}
+
+func (f SortField) String() string {
+	if f.Desc {
+		return "-" + f.Name
+	}
+	return f.Name
+}
+
+// ParseSort parses comma separated fields prefixed with - for descending order, e.g. -year,mark.
+// Only fields of the schema can be used.
+func (s *Schema) ParseSort(str string) ([]SortField, error) {
+	if str == "" {
+		return nil, nil
+	}
+
+	var fields []SortField
+	for _, part := range strings.Split(str, ",") {
+		name, desc := strings.CutPrefix(strings.TrimSpace(part), "-")
+		if _, ok := s.Field(name); !ok {
+			return nil, fmt.Errorf("failed to parse sort: %w %q", ErrUnknownField, name)
+		}
+
+		fields = append(fields, SortField{Name: name, Desc: desc})
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,276 @@
+// Package openapi validates HTTP requests against Swagger 2.0 spec: path, query and JSON body parameters
+// of the matched operation.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-openapi/spec"
+)
+
+// ErrUnreadableBody is returned if body of the request can't be read, e.g. it exceeds http.MaxBytesReader limit.
+var ErrUnreadableBody = errors.New("can't read body")
+
+// Violation is a single mismatch between the request and the spec.
+type Violation struct {
+	// In is location of the value: path, query or body.
+	In string `json:"in"`
+	// Name is parameter name or path to the body field, e.g. regNumber[0]. It is empty for the whole body.
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+type Validator struct {
+	definitions spec.Definitions
+	routes      []route
+	patterns    sync.Map
+}
+
+type route struct {
+	method     string
+	segments   []segment
+	literals   int
+	parameters []spec.Parameter
+}
+
+// segment is either literal part of the path or, if param is set, path parameter.
+type segment struct {
+	literal string
+	param   string
+}
+
+// NewValidator returns validator of requests to operations described by doc, JSON of Swagger 2.0 spec.
+func NewValidator(doc []byte) (*Validator, error) {
+	var swagger spec.Swagger
+	if err := json.Unmarshal(doc, &swagger); err != nil {
+		return nil, fmt.Errorf("can't parse spec: %w", err)
+	}
+
+	v := &Validator{
+		definitions: swagger.Definitions,
+	}
+	if swagger.Paths == nil {
+		return v, nil
+	}
+
+	for path, item := range swagger.Paths.Paths {
+		operations := map[string]*spec.Operation{
+			http.MethodGet:     item.Get,
+			http.MethodPut:     item.Put,
+			http.MethodPost:    item.Post,
+			http.MethodDelete:  item.Delete,
+			http.MethodOptions: item.Options,
+			http.MethodHead:    item.Head,
+			http.MethodPatch:   item.Patch,
+		}
+
+		for method, op := range operations {
+			if op == nil {
+				continue
+			}
+			v.routes = append(v.routes, newRoute(method, path, mergeParameters(item.Parameters, op.Parameters)))
+		}
+	}
+
+	// literal segments win over parameters, e.g. /cars/events over /cars/{regNumber}
+	sort.SliceStable(v.routes, func(i, j int) bool {
+		return v.routes[i].literals > v.routes[j].literals
+	})
+
+	return v, nil
+}
+
+func newRoute(method, path string, parameters []spec.Parameter) route {
+	rt := route{
+		method:     method,
+		parameters: parameters,
+	}
+
+	for _, part := range splitPath(path) {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			rt.segments = append(rt.segments, segment{param: part[1 : len(part)-1]})
+			continue
+		}
+
+		rt.segments = append(rt.segments, segment{literal: part})
+		rt.literals++
+	}
+
+	return rt
+}
+
+// mergeParameters returns parameters of the operation together with common ones of its path, the operation ones
+// override common ones with the same name and location.
+func mergeParameters(common, own []spec.Parameter) []spec.Parameter {
+	parameters := append([]spec.Parameter(nil), own...)
+	for _, param := range common {
+		overridden := false
+		for _, ownParam := range own {
+			if ownParam.Name == param.Name && ownParam.In == param.In {
+				overridden = true
+				break
+			}
+		}
+
+		if !overridden {
+			parameters = append(parameters, param)
+		}
+	}
+
+	return parameters
+}
+
+// Validate returns violations of the spec found in r. The path is relative to base path of the spec, requests
+// to operations missing from the spec aren't validated. Body of r is read and replaced, so it can be read again.
+// ErrUnreadableBody is returned if body can't be read, other errors mean the spec is broken, e.g. refers
+// to unknown definition.
+func (v *Validator) Validate(r *http.Request, path string) ([]Violation, error) {
+	rt, pathValues, ok := v.match(r.Method, path)
+	if !ok {
+		return nil, nil
+	}
+
+	var (
+		violations []Violation
+		query      = r.URL.Query()
+	)
+	for _, param := range rt.parameters {
+		var err error
+		switch param.In {
+		case "path":
+			err = v.validateParameter(param, []string{pathValues[param.Name]}, &violations)
+		case "query":
+			err = v.validateParameter(param, nonEmpty(query[param.Name]), &violations)
+		case "body":
+			err = v.validateBody(r, param, &violations)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return violations, nil
+}
+
+func (v *Validator) match(method, path string) (*route, map[string]string, bool) {
+	parts := splitPath(path)
+
+	for i := range v.routes {
+		rt := &v.routes[i]
+		if rt.method != method || len(rt.segments) != len(parts) {
+			continue
+		}
+
+		values := make(map[string]string)
+		matched := true
+		for j, seg := range rt.segments {
+			if seg.param != "" {
+				values[seg.param] = parts[j]
+				continue
+			}
+
+			if seg.literal != parts[j] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return rt, values, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+func (v *Validator) validateBody(r *http.Request, param spec.Parameter, violations *[]Violation) error {
+	var raw []byte
+	if r.Body != nil {
+		var err error
+		raw, err = io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrUnreadableBody, err)
+		}
+	}
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if param.Required {
+			*violations = append(*violations, Violation{In: param.In, Message: "is required"})
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var body any
+	if err := decoder.Decode(&body); err != nil {
+		*violations = append(*violations, Violation{In: param.In, Message: "must be valid JSON: " + err.Error()})
+		return nil
+	}
+
+	if param.Schema == nil {
+		return nil
+	}
+
+	return v.validateValue(body, param.Schema, param.In, "", violations)
+}
+
+func (v *Validator) pattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", expr, err)
+	}
+	v.patterns.Store(expr, re)
+
+	return re, nil
+}
+
+func (v *Validator) resolve(schema *spec.Schema) (*spec.Schema, error) {
+	for schema.Ref.String() != "" {
+		ref := schema.Ref.String()
+
+		def, ok := v.definitions[strings.TrimPrefix(ref, "#/definitions/")]
+		if !ok {
+			return nil, fmt.Errorf("unknown reference %s", ref)
+		}
+		schema = &def
+	}
+
+	return schema, nil
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
+
+// nonEmpty drops empty query values, they mean the same as missing parameter.
+func nonEmpty(values []string) []string {
+	var res []string
+	for _, value := range values {
+		if value != "" {
+			res = append(res, value)
+		}
+	}
+
+	return res
+}
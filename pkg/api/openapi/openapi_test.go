@@ -0,0 +1,413 @@
+package openapi_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/openapi"
+)
+
+const testSpec = `{
+	"swagger": "2.0",
+	"paths": {
+		"/cars": {
+			"get": {
+				"parameters": [
+					{"name": "limit", "in": "query", "type": "integer", "minimum": -1},
+					{"name": "mark", "in": "query", "type": "string", "enum": ["Lada", "Kia"]},
+					{"name": "ids", "in": "query", "type": "array", "items": {"type": "integer"}, "collectionFormat": "multi", "maxItems": 2},
+					{"name": "years", "in": "query", "type": "array", "items": {"type": "integer", "minimum": 1900}, "collectionFormat": "csv"},
+					{"name": "active", "in": "query", "type": "boolean"},
+					{"name": "since", "in": "query", "type": "string", "format": "date"},
+					{"name": "owner", "in": "query", "type": "string", "required": true, "minLength": 2}
+				]
+			},
+			"post": {
+				"parameters": [
+					{"name": "input", "in": "body", "required": true, "schema": {"$ref": "#/definitions/AddCars"}}
+				]
+			}
+		},
+		"/cars/events": {
+			"get": {
+				"parameters": [
+					{"name": "lastEventId", "in": "query", "type": "integer", "minimum": 0}
+				]
+			}
+		},
+		"/cars/{regNumber}": {
+			"parameters": [
+				{"name": "regNumber", "in": "path", "required": true, "type": "string", "pattern": "^[A-Z0-9]+$"}
+			],
+			"get": {},
+			"put": {
+				"parameters": [
+					{"name": "input", "in": "body", "schema": {"$ref": "#/definitions/Car"}}
+				]
+			}
+		},
+		"/broken": {
+			"post": {
+				"parameters": [
+					{"name": "input", "in": "body", "schema": {"$ref": "#/definitions/Missing"}}
+				]
+			}
+		}
+	},
+	"definitions": {
+		"AddCars": {
+			"type": "object",
+			"required": ["regNums"],
+			"properties": {
+				"regNums": {
+					"type": "array",
+					"minItems": 1,
+					"uniqueItems": true,
+					"items": {"type": "string", "minLength": 6, "maxLength": 9}
+				}
+			}
+		},
+		"Car": {
+			"type": "object",
+			"required": ["mark"],
+			"additionalProperties": false,
+			"properties": {
+				"mark": {"type": "string"},
+				"year": {"type": "integer", "minimum": 1900, "exclusiveMaximum": true, "maximum": 2100},
+				"price": {"type": "number", "multipleOf": 0.5, "x-nullable": true},
+				"owner": {"$ref": "#/definitions/Owner"},
+				"tags": {"type": "object", "additionalProperties": {"type": "string", "enum": ["new", "used"]}}
+			}
+		},
+		"Owner": {
+			"allOf": [
+				{"$ref": "#/definitions/Person"},
+				{"type": "object", "properties": {"since": {"type": "string", "format": "date-time"}}}
+			]
+		},
+		"Person": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "minLength": 1}
+			}
+		}
+	}
+}`
+
+func newValidator(t *testing.T) *openapi.Validator {
+	t.Helper()
+
+	v, err := openapi.NewValidator([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("NewValidator: %v", err)
+	}
+	return v
+}
+
+func TestValidate(t *testing.T) {
+	v := newValidator(t)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   []openapi.Violation
+	}{
+		{
+			name:   "valid query",
+			method: http.MethodGet,
+			target: "/cars?limit=10&mark=Lada&ids=1&ids=2&years=2010,2020&active=true&since=2024-01-02&owner=Ivan",
+		},
+		{
+			name:   "empty query values are missing",
+			method: http.MethodGet,
+			target: "/cars?limit=&mark=&owner=Ivan",
+		},
+		{
+			name:   "required query parameter",
+			method: http.MethodGet,
+			target: "/cars?owner=",
+			want: []openapi.Violation{
+				{In: "query", Name: "owner", Message: "is required"},
+			},
+		},
+		{
+			name:   "query types",
+			method: http.MethodGet,
+			target: "/cars?limit=ten&active=yes&since=02.01.2024&owner=I",
+			want: []openapi.Violation{
+				{In: "query", Name: "limit", Message: "must be integer"},
+				{In: "query", Name: "active", Message: "must be boolean"},
+				{In: "query", Name: "since", Message: "must be date in YYYY-MM-DD format"},
+				{In: "query", Name: "owner", Message: "length must be at least 2"},
+			},
+		},
+		{
+			name:   "query minimum and enum",
+			method: http.MethodGet,
+			target: "/cars?limit=-2&mark=Toyota&owner=Ivan",
+			want: []openapi.Violation{
+				{In: "query", Name: "limit", Message: "must be greater than or equal to -1"},
+				{In: "query", Name: "mark", Message: "must be one of Lada, Kia"},
+			},
+		},
+		{
+			name:   "repeated query parameter",
+			method: http.MethodGet,
+			target: "/cars?limit=1&limit=x&owner=Ivan",
+			want: []openapi.Violation{
+				{In: "query", Name: "limit", Message: "must be integer"},
+			},
+		},
+		{
+			name:   "multi array item type",
+			method: http.MethodGet,
+			target: "/cars?ids=1&ids=x&owner=Ivan",
+			want: []openapi.Violation{
+				{In: "query", Name: "ids", Message: "must be array of integer"},
+			},
+		},
+		{
+			name:   "multi array max items",
+			method: http.MethodGet,
+			target: "/cars?ids=1&ids=2&ids=3&owner=Ivan",
+			want: []openapi.Violation{
+				{In: "query", Name: "ids", Message: "must have at most 2 items"},
+			},
+		},
+		{
+			name:   "csv array items",
+			method: http.MethodGet,
+			target: "/cars?years=2010,1800,1850&owner=Ivan",
+			want: []openapi.Violation{
+				{In: "query", Name: "years[1]", Message: "must be greater than or equal to 1900"},
+				{In: "query", Name: "years[2]", Message: "must be greater than or equal to 1900"},
+			},
+		},
+		{
+			name:   "path pattern",
+			method: http.MethodPut,
+			target: "/cars/a111aa",
+			body:   `{"mark": "Lada"}`,
+			want: []openapi.Violation{
+				{In: "path", Name: "regNumber", Message: "must match pattern ^[A-Z0-9]+$"},
+			},
+		},
+		{
+			name:   "literal segment wins over path parameter",
+			method: http.MethodGet,
+			target: "/cars/events?lastEventId=-1",
+			want: []openapi.Violation{
+				{In: "query", Name: "lastEventId", Message: "must be greater than or equal to 0"},
+			},
+		},
+		{
+			name:   "path parameter route",
+			method: http.MethodGet,
+			target: "/cars/A111AA?lastEventId=-1",
+		},
+		{
+			name:   "unknown path",
+			method: http.MethodGet,
+			target: "/owners?limit=x",
+		},
+		{
+			name:   "unknown method",
+			method: http.MethodDelete,
+			target: "/cars?limit=x",
+		},
+		{
+			name:   "valid body",
+			method: http.MethodPost,
+			target: "/cars",
+			body:   `{"regNums": ["A111AA111", "B222BB222"]}`,
+		},
+		{
+			name:   "missing required body",
+			method: http.MethodPost,
+			target: "/cars",
+			body:   " \n",
+			want: []openapi.Violation{
+				{In: "body", Message: "is required"},
+			},
+		},
+		{
+			name:   "missing optional body",
+			method: http.MethodPut,
+			target: "/cars/A111AA",
+		},
+		{
+			name:   "malformed JSON",
+			method: http.MethodPost,
+			target: "/cars",
+			body:   `{"regNums": [`,
+			want: []openapi.Violation{
+				{In: "body", Message: "must be valid JSON: unexpected EOF"},
+			},
+		},
+		{
+			name:   "null body",
+			method: http.MethodPost,
+			target: "/cars",
+			body:   `null`,
+			want: []openapi.Violation{
+				{In: "body", Message: "must not be null"},
+			},
+		},
+		{
+			name:   "body of wrong type",
+			method: http.MethodPost,
+			target: "/cars",
+			body:   `["A111AA111"]`,
+			want: []openapi.Violation{
+				{In: "body", Message: "must be object"},
+			},
+		},
+		{
+			name:   "missing required property",
+			method: http.MethodPost,
+			target: "/cars",
+			body:   `{"regNums": null}`,
+			want: []openapi.Violation{
+				{In: "body", Name: "regNums", Message: "is required"},
+			},
+		},
+		{
+			name:   "every array violation",
+			method: http.MethodPost,
+			target: "/cars",
+			body:   `{"regNums": ["A1", "A1", 5, "A111AA1111"]}`,
+			want: []openapi.Violation{
+				{In: "body", Name: "regNums", Message: "must have unique items"},
+				{In: "body", Name: "regNums[0]", Message: "length must be at least 6"},
+				{In: "body", Name: "regNums[1]", Message: "length must be at least 6"},
+				{In: "body", Name: "regNums[2]", Message: "must be string"},
+				{In: "body", Name: "regNums[3]", Message: "length must be at most 9"},
+			},
+		},
+		{
+			name:   "empty array",
+			method: http.MethodPost,
+			target: "/cars",
+			body:   `{"regNums": []}`,
+			want: []openapi.Violation{
+				{In: "body", Name: "regNums", Message: "must have at least 1 items"},
+			},
+		},
+		{
+			name:   "every object violation through references",
+			method: http.MethodPut,
+			target: "/cars/A111AA",
+			body:   `{"year": 1800, "owner": {"since": "yesterday"}, "color": "red", "tags": {"state": "broken"}}`,
+			want: []openapi.Violation{
+				{In: "body", Name: "mark", Message: "is required"},
+				{In: "body", Name: "color", Message: "is not allowed"},
+				{In: "body", Name: "owner.name", Message: "is required"},
+				{In: "body", Name: "owner.since", Message: "must be RFC 3339 date-time"},
+				{In: "body", Name: "tags.state", Message: "must be one of new, used"},
+				{In: "body", Name: "year", Message: "must be greater than or equal to 1900"},
+			},
+		},
+		{
+			name:   "numbers",
+			method: http.MethodPut,
+			target: "/cars/A111AA",
+			body:   `{"mark": "Lada", "year": 2000.5, "price": 10.3}`,
+			want: []openapi.Violation{
+				{In: "body", Name: "price", Message: "must be multiple of 0.5"},
+				{In: "body", Name: "year", Message: "must be integer"},
+			},
+		},
+		{
+			name:   "exclusive maximum",
+			method: http.MethodPut,
+			target: "/cars/A111AA",
+			body:   `{"mark": "Lada", "year": 2100}`,
+			want: []openapi.Violation{
+				{In: "body", Name: "year", Message: "must be less than 2100"},
+			},
+		},
+		{
+			name:   "nullable property",
+			method: http.MethodPut,
+			target: "/cars/A111AA",
+			body:   `{"mark": "Lada", "price": null, "owner": {"name": "Ivan", "since": "2024-01-02T03:04:05Z"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+
+			got, err := v.Validate(r, r.URL.Path)
+			if err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("violations = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateKeepsBody(t *testing.T) {
+	v := newValidator(t)
+
+	body := `{"regNums": ["A111AA111"]}`
+	r := httptest.NewRequest(http.MethodPost, "/cars", strings.NewReader(body))
+
+	if _, err := v.Validate(r, r.URL.Path); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	got, err := io.ReadAll(r.Body)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(got) != body {
+		t.Errorf("body = %q, want %q", got, body)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	v := newValidator(t)
+
+	t.Run("unknown reference", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/broken", strings.NewReader(`{}`))
+
+		_, err := v.Validate(r, r.URL.Path)
+		if err == nil || errors.Is(err, openapi.ErrUnreadableBody) || !strings.Contains(err.Error(), "#/definitions/Missing") {
+			t.Errorf("err = %v, want unknown reference error", err)
+		}
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/cars", strings.NewReader(`{"regNums": ["A111AA111"]}`))
+		r.Body = http.MaxBytesReader(w, r.Body, 8)
+
+		_, err := v.Validate(r, r.URL.Path)
+		if !errors.Is(err, openapi.ErrUnreadableBody) {
+			t.Fatalf("err = %v, want %v", err, openapi.ErrUnreadableBody)
+		}
+
+		var maxBytesErr *http.MaxBytesError
+		if !errors.As(err, &maxBytesErr) {
+			t.Errorf("err = %v, want it to wrap *http.MaxBytesError", err)
+		}
+	})
+}
+
+func TestNewValidatorInvalidSpec(t *testing.T) {
+	if _, err := openapi.NewValidator([]byte(`{"swagger": `)); err == nil {
+		t.Error("NewValidator of malformed spec succeeded")
+	}
+}
@@ -0,0 +1,409 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-openapi/spec"
+)
+
+const (
+	typeObject  = "object"
+	typeArray   = "array"
+	typeString  = "string"
+	typeInteger = "integer"
+	typeNumber  = "number"
+	typeBoolean = "boolean"
+)
+
+// validateParameter checks raw values of path or query parameter, there are several of them only for query
+// parameters repeated in the url.
+func (v *Validator) validateParameter(param spec.Parameter, values []string, violations *[]Violation) error {
+	if len(values) == 0 || (param.In == "path" && values[0] == "") {
+		if param.Required {
+			*violations = append(*violations, Violation{In: param.In, Name: param.Name, Message: "is required"})
+		}
+		return nil
+	}
+
+	schema := simpleSchema(param.SimpleSchema, param.CommonValidations)
+	mismatch := Violation{In: param.In, Name: param.Name, Message: "must be " + typeName(param.SimpleSchema)}
+
+	if param.Type == typeArray && param.CollectionFormat == "multi" && param.Items != nil {
+		// every repeated parameter is an item
+		items := make([]any, 0, len(values))
+		for _, raw := range values {
+			item, ok := parseSimple(raw, param.Items.SimpleSchema)
+			if !ok {
+				*violations = append(*violations, mismatch)
+				return nil
+			}
+			items = append(items, item)
+		}
+
+		return v.validateValue(items, schema, param.In, param.Name, violations)
+	}
+
+	for _, raw := range values {
+		value, ok := parseSimple(raw, param.SimpleSchema)
+		if !ok {
+			*violations = append(*violations, mismatch)
+			continue
+		}
+
+		if err := v.validateValue(value, schema, param.In, param.Name, violations); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// simpleSchema returns schema of non-body parameter or its items.
+func simpleSchema(simple spec.SimpleSchema, validations spec.CommonValidations) *spec.Schema {
+	schema := &spec.Schema{
+		SchemaProps: spec.SchemaProps{
+			Type:             spec.StringOrArray{simple.Type},
+			Format:           simple.Format,
+			Maximum:          validations.Maximum,
+			ExclusiveMaximum: validations.ExclusiveMaximum,
+			Minimum:          validations.Minimum,
+			ExclusiveMinimum: validations.ExclusiveMinimum,
+			MaxLength:        validations.MaxLength,
+			MinLength:        validations.MinLength,
+			Pattern:          validations.Pattern,
+			MaxItems:         validations.MaxItems,
+			MinItems:         validations.MinItems,
+			UniqueItems:      validations.UniqueItems,
+			MultipleOf:       validations.MultipleOf,
+			Enum:             validations.Enum,
+		},
+	}
+
+	if simple.Items != nil {
+		schema.Items = &spec.SchemaOrArray{Schema: simpleSchema(simple.Items.SimpleSchema, simple.Items.CommonValidations)}
+	}
+
+	return schema
+}
+
+func typeName(simple spec.SimpleSchema) string {
+	if simple.Type == typeArray && simple.Items != nil {
+		return "array of " + simple.Items.Type
+	}
+
+	return simple.Type
+}
+
+// parseSimple converts raw parameter value to the value of JSON decoded with json.Decoder.UseNumber.
+func parseSimple(raw string, simple spec.SimpleSchema) (any, bool) {
+	switch simple.Type {
+	case typeInteger:
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, false
+		}
+		return json.Number(raw), true
+	case typeNumber:
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return nil, false
+		}
+		return json.Number(raw), true
+	case typeBoolean:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, false
+		}
+		return b, true
+	case typeArray:
+		if simple.Items == nil {
+			return nil, false
+		}
+
+		var items []any
+		for _, rawItem := range strings.Split(raw, collectionSeparator(simple.CollectionFormat)) {
+			item, ok := parseSimple(rawItem, simple.Items.SimpleSchema)
+			if !ok {
+				return nil, false
+			}
+			items = append(items, item)
+		}
+		return items, true
+	default:
+		return raw, true
+	}
+}
+
+func collectionSeparator(format string) string {
+	switch format {
+	case "ssv":
+		return " "
+	case "tsv":
+		return "\t"
+	case "pipes":
+		return "|"
+	default:
+		return ","
+	}
+}
+
+// validateValue checks decoded JSON value against schema and appends found violations. Null values of properties
+// which aren't required are treated as missing ones.
+func (v *Validator) validateValue(value any, schema *spec.Schema, in, name string, violations *[]Violation) error {
+	schema, err := v.resolve(schema)
+	if err != nil {
+		return err
+	}
+
+	violate := func(format string, args ...any) {
+		*violations = append(*violations, Violation{In: in, Name: name, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for i := range schema.AllOf {
+		if err = v.validateValue(value, &schema.AllOf[i], in, name, violations); err != nil {
+			return err
+		}
+	}
+
+	if value == nil {
+		if len(schema.Type) > 0 && !nullable(schema) {
+			violate("must not be null")
+		}
+		return nil
+	}
+
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(typ string) bool { return hasType(value, typ) }) {
+		violate("must be %s", strings.Join(schema.Type, " or "))
+		return nil
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(value, schema.Enum) {
+		violate("must be one of %s", formatEnum(schema.Enum))
+	}
+
+	switch value := value.(type) {
+	case string:
+		length := int64(utf8.RuneCountInString(value))
+		if schema.MinLength != nil && length < *schema.MinLength {
+			violate("length must be at least %d", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			violate("length must be at most %d", *schema.MaxLength)
+		}
+
+		if schema.Pattern != "" {
+			re, err := v.pattern(schema.Pattern)
+			if err != nil {
+				return err
+			}
+			if !re.MatchString(value) {
+				violate("must match pattern %s", schema.Pattern)
+			}
+		}
+
+		if msg, ok := checkFormat(value, schema.Format); !ok {
+			violate("%s", msg)
+		}
+	case json.Number:
+		number, _ := value.Float64()
+		if schema.Minimum != nil && (number < *schema.Minimum || schema.ExclusiveMinimum && number == *schema.Minimum) {
+			violate("must be %s %s", comparison("greater than", schema.ExclusiveMinimum), formatNumber(*schema.Minimum))
+		}
+		if schema.Maximum != nil && (number > *schema.Maximum || schema.ExclusiveMaximum && number == *schema.Maximum) {
+			violate("must be %s %s", comparison("less than", schema.ExclusiveMaximum), formatNumber(*schema.Maximum))
+		}
+		if schema.MultipleOf != nil && *schema.MultipleOf != 0 {
+			if q := number / *schema.MultipleOf; q != math.Trunc(q) {
+				violate("must be multiple of %s", formatNumber(*schema.MultipleOf))
+			}
+		}
+	case []any:
+		if schema.MinItems != nil && int64(len(value)) < *schema.MinItems {
+			violate("must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && int64(len(value)) > *schema.MaxItems {
+			violate("must have at most %d items", *schema.MaxItems)
+		}
+		if schema.UniqueItems && hasDuplicates(value) {
+			violate("must have unique items")
+		}
+
+		if schema.Items != nil && schema.Items.Schema != nil {
+			for i, item := range value {
+				if err = v.validateValue(item, schema.Items.Schema, in, fmt.Sprintf("%s[%d]", name, i), violations); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		return v.validateObject(value, schema, in, name, violations)
+	}
+
+	return nil
+}
+
+func (v *Validator) validateObject(object map[string]any, schema *spec.Schema, in, name string, violations *[]Violation) error {
+	for _, required := range schema.Required {
+		if object[required] == nil {
+			*violations = append(*violations, Violation{In: in, Name: fieldName(name, required), Message: "is required"})
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := object[key]
+		if value == nil {
+			// missing required properties are reported above
+			continue
+		}
+
+		field := fieldName(name, key)
+		if property, ok := schema.Properties[key]; ok {
+			if err := v.validateValue(value, &property, in, field, violations); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if additional := schema.AdditionalProperties; additional != nil {
+			if additional.Schema != nil {
+				if err := v.validateValue(value, additional.Schema, in, field, violations); err != nil {
+					return err
+				}
+			} else if !additional.Allows {
+				*violations = append(*violations, Violation{In: in, Name: field, Message: "is not allowed"})
+			}
+		}
+	}
+
+	return nil
+}
+
+func hasType(value any, typ string) bool {
+	switch typ {
+	case typeObject:
+		_, ok := value.(map[string]any)
+		return ok
+	case typeArray:
+		_, ok := value.([]any)
+		return ok
+	case typeString:
+		_, ok := value.(string)
+		return ok
+	case typeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case typeNumber:
+		_, ok := value.(json.Number)
+		return ok
+	case typeInteger:
+		number, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseInt(number.String(), 10, 64)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+func nullable(schema *spec.Schema) bool {
+	if schema.Nullable {
+		return true
+	}
+
+	xNullable, _ := schema.Extensions["x-nullable"].(bool)
+	return xNullable
+}
+
+func checkFormat(value, format string) (string, bool) {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be RFC 3339 date-time", false
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return "must be date in YYYY-MM-DD format", false
+		}
+	}
+
+	return "", true
+}
+
+func inEnum(value any, enum []any) bool {
+	for _, allowed := range enum {
+		if equal(value, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equal compares decoded request value with value from the spec, numbers of the spec are float64.
+func equal(value, allowed any) bool {
+	if number, ok := value.(json.Number); ok {
+		f, err := number.Float64()
+		if err != nil {
+			return false
+		}
+		value = f
+	}
+
+	return reflect.DeepEqual(value, allowed)
+}
+
+func hasDuplicates(items []any) bool {
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if reflect.DeepEqual(items[i], items[j]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func formatEnum(enum []any) string {
+	values := make([]string, 0, len(enum))
+	for _, value := range enum {
+		values = append(values, fmt.Sprint(value))
+	}
+
+	return strings.Join(values, ", ")
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+func comparison(base string, exclusive bool) string {
+	if exclusive {
+		return base
+	}
+
+	return base + " or equal to"
+}
+
+func fieldName(parent, field string) string {
+	if parent == "" {
+		return field
+	}
+
+	return parent + "." + field
+}
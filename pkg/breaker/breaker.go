@@ -0,0 +1,171 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type Config struct {
+	// FailureThreshold is a number of consecutive failures opening the breaker.
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting trial requests through.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is a number of trial requests, all of them must succeed to close the breaker.
+	HalfOpenRequests int
+}
+
+// Status is a snapshot of the breaker state.
+type Status struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
+// Breaker stops requests to a failing dependency. It is safe for concurrent use.
+//
+// Callers ask Allow before a request and then report its outcome with Success, Failure or Cancel.
+type Breaker struct {
+	mu            sync.Mutex
+	cfg           Config
+	onStateChange func(from, to State)
+
+	state     State
+	failures  int
+	openedAt  time.Time
+	inFlight  int
+	successes int
+}
+
+// New returns closed breaker. onStateChange, if not nil, is called on every transition while the lock is held,
+// so it must not call the breaker.
+func New(cfg Config, onStateChange func(from, to State)) *Breaker {
+	cfg.FailureThreshold = max(cfg.FailureThreshold, 1)
+	cfg.HalfOpenRequests = max(cfg.HalfOpenRequests, 1)
+
+	return &Breaker{
+		cfg:           cfg,
+		onStateChange: onStateChange,
+	}
+}
+
+// Allow returns ErrOpen if request mustn't be made.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen {
+		if time.Since(b.openedAt) < b.cfg.OpenTimeout {
+			return ErrOpen
+		}
+		b.setState(StateHalfOpen)
+	}
+
+	if b.state == StateHalfOpen {
+		if b.inFlight >= b.cfg.HalfOpenRequests-b.successes {
+			return ErrOpen
+		}
+		b.inFlight++
+	}
+
+	return nil
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		b.failures = 0
+	case StateHalfOpen:
+		b.inFlight = max(b.inFlight-1, 0)
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenRequests {
+			b.setState(StateClosed)
+		}
+	}
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.setState(StateOpen)
+		}
+	case StateHalfOpen:
+		b.failures++
+		b.setState(StateOpen)
+	}
+}
+
+// Cancel reports request which neither succeeded nor failed, e.g. cancelled by the caller.
+func (b *Breaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateHalfOpen {
+		b.inFlight = max(b.inFlight-1, 0)
+	}
+}
+
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := Status{
+		State:               b.state.String(),
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+
+	return status
+}
+
+func (b *Breaker) setState(state State) {
+	from := b.state
+	b.state = state
+	b.inFlight = 0
+	b.successes = 0
+
+	switch state {
+	case StateOpen:
+		b.openedAt = time.Now()
+	case StateClosed:
+		b.failures = 0
+	}
+
+	if b.onStateChange != nil {
+		b.onStateChange(from, state)
+	}
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrCantAuthenticate = errors.New("can't authenticate request")
+
+// Authenticator adds credentials to every request made by HTTPClient.
+type Authenticator interface {
+	Authenticate(ctx context.Context, r *http.Request) error
+}
+
+// invalidator is implemented by authenticators whose credentials can expire before they are told so.
+// HTTPClient invalidates credentials and retries once when a request is answered with 401.
+type invalidator interface {
+	Invalidate()
+}
+
+// APIKeyAuth sends static key in a header.
+type APIKeyAuth struct {
+	header string
+	key    string
+}
+
+func NewAPIKeyAuth(header, key string) *APIKeyAuth {
+	return &APIKeyAuth{
+		header: header,
+		key:    key,
+	}
+}
+
+func (a *APIKeyAuth) Authenticate(ctx context.Context, r *http.Request) error {
+	r.Header.Set(a.header, a.key)
+	return nil
+}
+
+// BearerTokenAuth gets token from OAuth2 token endpoint with client credentials grant
+// and sends it in Authorization header. The token is refreshed shortly before it expires.
+type BearerTokenAuth struct {
+	client       *http.Client
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scope        string
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// tokenExpirySkew is how long before expiration the token is refreshed.
+const tokenExpirySkew = 30 * time.Second
+
+func NewBearerTokenAuth(client *http.Client, tokenURL, clientID, clientSecret, scope string) *BearerTokenAuth {
+	return &BearerTokenAuth{
+		client:       client,
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scope:        scope,
+	}
+}
+
+func (a *BearerTokenAuth) Authenticate(ctx context.Context, r *http.Request) error {
+	token, err := a.getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+func (a *BearerTokenAuth) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = ""
+}
+
+func (a *BearerTokenAuth) getToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && (a.expiresAt.IsZero() || time.Now().Before(a.expiresAt)) {
+		return a.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	form.Set("client_id", a.clientID)
+	form.Set("client_secret", a.clientSecret)
+	if a.scope != "" {
+		form.Set("scope", a.scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w: %w", ErrCantAuthenticate, ErrCantCreateRequest, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: can't get token: %w", ErrCantAuthenticate, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return "", fmt.Errorf("%w: can't get token: %w", ErrCantAuthenticate, &StatusCodeError{URL: a.tokenURL, StatusCode: res.StatusCode})
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("%w: can't decode token: %w", ErrCantAuthenticate, err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("%w: token endpoint returned empty token", ErrCantAuthenticate)
+	}
+
+	a.token = token.AccessToken
+	a.expiresAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		a.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpirySkew)
+	}
+
+	return a.token, nil
+}
+
+// NewTLSConfig returns config for mutual TLS. Client certificate is used if certFile and keyFile are set,
+// setting only one of them is an error. Server certificates are verified with caFile if it is set and
+// with system roots otherwise.
+func NewTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, fmt.Errorf("client certificate and key files must be set together")
+	}
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca certificate: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse ca certificate %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,46 @@
+package carinfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/breaker"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client"
+)
+
+// BreakerClient fails fast with client.ErrUpstreamUnavailable while the breaker is open.
+type BreakerClient struct {
+	client  carInfoClient
+	breaker *breaker.Breaker
+}
+
+func NewBreakerClient(client carInfoClient, breaker *breaker.Breaker) *BreakerClient {
+	return &BreakerClient{
+		client:  client,
+		breaker: breaker,
+	}
+}
+
+func (c *BreakerClient) GetCarInfoByRegNumber(ctx context.Context, regNumber string) (CarInfo, error) {
+	if err := c.breaker.Allow(); err != nil {
+		return CarInfo{}, fmt.Errorf("can't get car info: %w: %w", client.ErrUpstreamUnavailable, err)
+	}
+
+	res, err := c.client.GetCarInfoByRegNumber(ctx, regNumber)
+	switch {
+	case err == nil, errors.Is(err, ErrNotFound), errors.Is(err, ErrBadRequest), errors.Is(err, ErrUnauthorized):
+		// the API answered, so it is up
+		c.breaker.Success()
+	case ctx.Err() != nil:
+		c.breaker.Cancel()
+	default:
+		c.breaker.Failure()
+	}
+
+	return res, err
+}
+
+func (c *BreakerClient) Status() breaker.Status {
+	return c.breaker.Status()
+}
@@ -0,0 +1,168 @@
+package carinfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/cache"
+)
+
+// storeTimeout limits writes to the store, they don't depend on the request which got the answer.
+const storeTimeout = 5 * time.Second
+
+type bypassCacheKey struct{}
+
+// WithCacheBypass returns context that makes CachedClient skip cached entries and ask the API again.
+// The fresh answer is still cached.
+func WithCacheBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+// CacheEntry is a cached API answer. NotFound entries remember that the API doesn't know the number.
+type CacheEntry struct {
+	RegNumber string
+	Info      CarInfo
+	NotFound  bool
+	ExpiresAt time.Time
+}
+
+// Store persists cache entries, so they survive restarts.
+type Store interface {
+	GetCarInfo(ctx context.Context, regNumber string) (CacheEntry, bool, error)
+	SetCarInfo(ctx context.Context, entry CacheEntry) error
+	// DeleteExpiredCarInfo deletes entries expired before now and returns their number.
+	DeleteExpiredCarInfo(ctx context.Context, now time.Time) (int64, error)
+}
+
+type carInfoClient interface {
+	GetCarInfoByRegNumber(ctx context.Context, regNumber string) (CarInfo, error)
+}
+
+// CachedClient caches answers of the car info API. Successful answers live for ttl and
+// ErrNotFound answers live for negativeTTL.
+type CachedClient struct {
+	log         *slog.Logger
+	client      carInfoClient
+	memory      *cache.LRU[string, CacheEntry]
+	store       Store
+	ttl         time.Duration
+	negativeTTL time.Duration
+}
+
+// NewCachedClient returns client caching answers in memory and, if store is not nil, in the store.
+func NewCachedClient(log *slog.Logger, client carInfoClient, store Store, size int, ttl, negativeTTL time.Duration) *CachedClient {
+	return &CachedClient{
+		log:         log.With(slog.String("component", "car_info_cache")),
+		client:      client,
+		memory:      cache.NewLRU[string, CacheEntry](size, 0),
+		store:       store,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+	}
+}
+
+func (c *CachedClient) GetCarInfoByRegNumber(ctx context.Context, regNumber string) (CarInfo, error) {
+	if !cacheBypassed(ctx) {
+		if entry, ok := c.lookup(ctx, regNumber); ok {
+			if entry.NotFound {
+				return CarInfo{}, fmt.Errorf("can't get car info: %w (cached)", ErrNotFound)
+			}
+			return entry.Info, nil
+		}
+	}
+
+	info, err := c.client.GetCarInfoByRegNumber(ctx, regNumber)
+	switch {
+	case err == nil:
+		c.save(ctx, CacheEntry{RegNumber: regNumber, Info: info, ExpiresAt: time.Now().Add(c.ttl)})
+	case errors.Is(err, ErrNotFound):
+		c.save(ctx, CacheEntry{RegNumber: regNumber, NotFound: true, ExpiresAt: time.Now().Add(c.negativeTTL)})
+	}
+
+	return info, err
+}
+
+func (c *CachedClient) Stats() cache.Stats {
+	return c.memory.Stats()
+}
+
+func (c *CachedClient) lookup(ctx context.Context, regNumber string) (CacheEntry, bool) {
+	now := time.Now()
+
+	if entry, ok := c.memory.Get(regNumber); ok {
+		if now.Before(entry.ExpiresAt) {
+			return entry, true
+		}
+		c.memory.Delete(regNumber)
+	}
+
+	if c.store == nil {
+		return CacheEntry{}, false
+	}
+
+	// the store is only an optimization, so if it fails the API is asked
+	entry, ok, err := c.store.GetCarInfo(ctx, regNumber)
+	if err != nil {
+		c.log.Error("failed to get car info from store", slog.String("reg_number", regNumber), slog.String("error", err.Error()))
+		return CacheEntry{}, false
+	}
+
+	if !ok || !now.Before(entry.ExpiresAt) {
+		return CacheEntry{}, false
+	}
+
+	c.memory.Set(regNumber, entry)
+	return entry, true
+}
+
+func (c *CachedClient) save(ctx context.Context, entry CacheEntry) {
+	c.memory.Set(entry.RegNumber, entry)
+
+	if c.store == nil {
+		return
+	}
+
+	// the answer is already paid for, so it is stored even if the request is cancelled
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storeTimeout)
+	defer cancel()
+
+	if err := c.store.SetCarInfo(ctx, entry); err != nil {
+		c.log.Error("failed to save car info to store", slog.String("reg_number", entry.RegNumber), slog.String("error", err.Error()))
+	}
+}
+
+// Run deletes expired entries from the store every interval until ctx is cancelled. Expired entries are never
+// returned, so it only keeps the store from growing.
+func (c *CachedClient) Run(ctx context.Context, interval time.Duration) {
+	if c.store == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			deleted, err := c.store.DeleteExpiredCarInfo(ctx, now)
+			if err != nil {
+				c.log.Error("failed to delete expired car info", slog.String("error", err.Error()))
+				continue
+			}
+
+			if deleted > 0 {
+				c.log.Debug("expired car info deleted", slog.Int64("count", deleted))
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package carinfo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -15,11 +16,18 @@ const (
 
 type Client struct {
 	httpClient *client.HTTPClient
+	method     string
 }
 
 func NewClient(httpClient *client.HTTPClient) *Client {
+	return NewClientWithMethod(httpClient, getCarInfoByRegNumberMethod)
+}
+
+// NewClientWithMethod returns client of API which serves car info at another path than /info.
+func NewClientWithMethod(httpClient *client.HTTPClient, method string) *Client {
 	return &Client{
 		httpClient: httpClient,
+		method:     method,
 	}
 }
 
@@ -37,20 +45,53 @@ type Owner struct {
 	Patronymic string `json:"patronymic,omitempty"`
 }
 
-func (c *Client) GetCarInfoByRegNumber(ctx context.Context, regNumber string) ([]byte, error) {
-	u := c.httpClient.GetUlrWithMethods(getCarInfoByRegNumberMethod)
+// Validate checks that fields required by the API spec are set.
+func (c CarInfo) Validate() error {
+	switch {
+	case c.RegNumber == "":
+		return fmt.Errorf("%w: regNum is required", ErrMalformed)
+	case c.Mark == "":
+		return fmt.Errorf("%w: mark is required", ErrMalformed)
+	case c.Model == "":
+		return fmt.Errorf("%w: model is required", ErrMalformed)
+	case c.Owner.Name == "":
+		return fmt.Errorf("%w: owner.name is required", ErrMalformed)
+	case c.Owner.Surname == "":
+		return fmt.Errorf("%w: owner.surname is required", ErrMalformed)
+	}
+
+	return nil
+}
+
+// GetCarInfoByRegNumber returns validated car info. Errors match one of ErrNotFound, ErrBadRequest, ErrUnauthorized,
+// ErrUpstream, ErrTimeout and ErrMalformed.
+func (c *Client) GetCarInfoByRegNumber(ctx context.Context, regNumber string) (CarInfo, error) {
+	if regNumber == "" {
+		return CarInfo{}, fmt.Errorf("can't get car info: %w: empty registration number", ErrBadRequest)
+	}
+
+	u := c.httpClient.GetUlrWithMethods(c.method)
 	q := url.Values{}
 	q.Add("regNum", regNumber)
 
 	req, err := c.httpClient.CreateRequest(ctx, http.MethodGet, u.String(), nil, nil, q)
 	if err != nil {
-		return nil, fmt.Errorf("can't get car info: %w", err)
+		return CarInfo{}, fmt.Errorf("can't get car info: %w: %w", ErrBadRequest, err)
 	}
 
 	res, err := c.httpClient.Do(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("can't get car info: %w", err)
+		return CarInfo{}, fmt.Errorf("can't get car info: %w: %w", classifyError(err), err)
+	}
+
+	var carInfo CarInfo
+	if err = json.Unmarshal(res, &carInfo); err != nil {
+		return CarInfo{}, fmt.Errorf("can't get car info: %w: %w", ErrMalformed, err)
+	}
+
+	if err = carInfo.Validate(); err != nil {
+		return CarInfo{}, fmt.Errorf("can't get car info: %w", err)
 	}
 
-	return res, nil
+	return carInfo, nil
 }
@@ -0,0 +1,102 @@
+package carinfo
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Dataset serves car info from a local file.
+type Dataset struct {
+	cars map[string]CarInfo
+}
+
+// LoadDataset reads JSON array of CarInfo or CSV file with header
+// regNum,mark,model,year,ownerName,ownerSurname,ownerPatronymic. Format is chosen by file extension.
+func LoadDataset(path string) (*Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	defer f.Close()
+
+	var infos []CarInfo
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.NewDecoder(f).Decode(&infos)
+	case ".csv":
+		infos, err = readCSV(f)
+	default:
+		err = errors.New("unknown file extension, expected .json or .csv")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset %s: %w", path, err)
+	}
+
+	cars := make(map[string]CarInfo, len(infos))
+	for i, info := range infos {
+		if err = info.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid car %d in dataset %s: %w", i+1, path, err)
+		}
+		cars[info.RegNumber] = info
+	}
+
+	return &Dataset{
+		cars: cars,
+	}, nil
+}
+
+func (d *Dataset) GetCarInfoByRegNumber(ctx context.Context, regNumber string) (CarInfo, error) {
+	info, ok := d.cars[regNumber]
+	if !ok {
+		return CarInfo{}, fmt.Errorf("can't find car info in dataset: %w", ErrNotFound)
+	}
+
+	return info, nil
+}
+
+func readCSV(r io.Reader) ([]CarInfo, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 7
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	infos := make([]CarInfo, 0, len(records)-1)
+	for i, record := range records[1:] {
+		var year int
+		if record[3] != "" {
+			year, err = strconv.Atoi(record[3])
+			if err != nil {
+				return nil, fmt.Errorf("invalid year on line %d: %w", i+2, err)
+			}
+		}
+
+		infos = append(infos, CarInfo{
+			RegNumber: record[0],
+			Mark:      record[1],
+			Model:     record[2],
+			Year:      year,
+			Owner: Owner{
+				Name:       record[4],
+				Surname:    record[5],
+				Patronymic: record[6],
+			},
+		})
+	}
+
+	return infos, nil
+}
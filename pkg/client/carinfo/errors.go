@@ -0,0 +1,44 @@
+package carinfo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client"
+)
+
+var (
+	// ErrNotFound means the API doesn't know the number, the API answers 400 or 404 in this case.
+	ErrNotFound = errors.New("car info not found")
+	// ErrBadRequest means the request can't be made, e.g. the number is empty.
+	ErrBadRequest = errors.New("bad car info request")
+	// ErrUnauthorized means the API rejected our credentials.
+	ErrUnauthorized = errors.New("unauthorized by car info api")
+	// ErrUpstream means the API is broken: it answered with unexpected status or couldn't be reached.
+	ErrUpstream = errors.New("car info api error")
+	// ErrTimeout means the API didn't answer in time.
+	ErrTimeout = errors.New("car info api timeout")
+	// ErrMalformed means the API answered with payload not matching the spec.
+	ErrMalformed = errors.New("malformed car info")
+)
+
+// classifyError maps error of client.HTTPClient to one of the errors above.
+func classifyError(err error) error {
+	var statusErr *client.StatusCodeError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusBadRequest || statusErr.StatusCode == http.StatusNotFound):
+		return ErrNotFound
+	case errors.Is(err, client.Err401StatusCode), errors.Is(err, client.ErrCantAuthenticate):
+		return ErrUnauthorized
+	case errors.Is(err, client.ErrCantCreateRequest):
+		return ErrBadRequest
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	default:
+		return ErrUpstream
+	}
+}
@@ -1,10 +1,49 @@
 package client
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
 
 var (
 	Err401StatusCode     = errors.New("unauthorized - 401")
 	Err400StatusCode     = errors.New("bad request - 400")
 	ErrWrongStatusCode   = errors.New("wrong status code")
 	ErrCantCreateRequest = errors.New("can't create request")
+	// ErrUpstreamUnavailable is returned without making request while the upstream is considered down.
+	ErrUpstreamUnavailable = errors.New("upstream unavailable")
 )
+
+// StatusCodeError is returned for responses with status other than 200. It matches Err400StatusCode,
+// Err401StatusCode or ErrWrongStatusCode depending on the status code.
+type StatusCodeError struct {
+	URL        string
+	StatusCode int
+	// Body is the beginning of the response body, APIs usually describe the error there.
+	Body []byte
+}
+
+func (e *StatusCodeError) Error() string {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return fmt.Sprintf("%s to %s", Err400StatusCode, e.URL)
+	case http.StatusUnauthorized:
+		return fmt.Sprintf("%s to %s", Err401StatusCode, e.URL)
+	default:
+		return fmt.Sprintf("%s to %s: %d", ErrWrongStatusCode, e.URL, e.StatusCode)
+	}
+}
+
+func (e *StatusCodeError) Is(target error) bool {
+	switch target {
+	case Err400StatusCode:
+		return e.StatusCode == http.StatusBadRequest
+	case Err401StatusCode:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrWrongStatusCode:
+		return e.StatusCode != http.StatusBadRequest && e.StatusCode != http.StatusUnauthorized
+	default:
+		return false
+	}
+}
@@ -9,35 +9,106 @@ import (
 	"path"
 )
 
+// maxErrorBodySize limits how much of unsuccessful response body is kept in StatusCodeError.
+const maxErrorBodySize = 64 << 10
+
 type HTTPClient struct {
-	Scheme   string
-	Host     string
-	BasePath string
-	client   http.Client
+	Scheme      string
+	Host        string
+	BasePath    string
+	client      http.Client
+	retryPolicy RetryPolicy
+	auth        Authenticator
 }
 
-func NewHTTPClient(host, basePath, scheme string, client http.Client) *HTTPClient {
+// NewHTTPClient returns client which authenticates every request with auth, nil auth means no authentication.
+func NewHTTPClient(host, basePath, scheme string, client http.Client, retryPolicy RetryPolicy, auth Authenticator) *HTTPClient {
 	return &HTTPClient{
-		Scheme:   scheme,
-		Host:     host,
-		BasePath: basePath,
-		client:   client,
+		Scheme:      scheme,
+		Host:        host,
+		BasePath:    basePath,
+		client:      client,
+		retryPolicy: retryPolicy,
+		auth:        auth,
 	}
 }
 
+// Do sends request and returns body of successful response. Network errors and retryable status codes are retried
+// according to the retry policy until attempts run out or ctx is done.
 func (hc *HTTPClient) Do(ctx context.Context, r *http.Request) ([]byte, error) {
-	res, err := hc.client.Do(r)
-	if err != nil {
-		return nil, fmt.Errorf("can't do request: %w", err)
+	attempts := hc.retryPolicy.attempts()
+	replayable := r.Body == nil || r.Body == http.NoBody || r.GetBody != nil
+	if !replayable {
+		attempts = 1
 	}
+
+	sent, reauthenticated := false, false
+	for attempt := 1; ; attempt++ {
+		req := r.Clone(ctx)
+		if sent && r.GetBody != nil {
+			body, err := r.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("can't do request: %w", err)
+			}
+			req.Body = body
+		}
+		sent = true
+
+		if hc.auth != nil {
+			if err := hc.auth.Authenticate(ctx, req); err != nil {
+				return nil, fmt.Errorf("can't do request: %w", err)
+			}
+		}
+
+		res, err := hc.client.Do(req)
+		if err != nil {
+			if attempt >= attempts || !isRetryableError(err) {
+				return nil, fmt.Errorf("can't do request: %w", err)
+			}
+
+			if err = sleep(ctx, hc.retryPolicy.backoff(attempt)); err != nil {
+				return nil, fmt.Errorf("can't do request: %w", err)
+			}
+			continue
+		}
+
+		if inv, ok := hc.auth.(invalidator); ok && res.StatusCode == http.StatusUnauthorized && !reauthenticated && replayable {
+			// credentials could expire, so get new ones once without spending an attempt
+			reauthenticated = true
+			inv.Invalidate()
+
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+			attempt--
+			continue
+		}
+
+		if attempt < attempts && hc.retryPolicy.retryableStatus(res.StatusCode) {
+			delay, ok := hc.retryPolicy.retryAfter(res.Header)
+			if !ok {
+				delay = hc.retryPolicy.backoff(attempt)
+			}
+
+			// drain body, so the connection can be reused
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+
+			if err = sleep(ctx, delay); err != nil {
+				return nil, fmt.Errorf("can't do request: %w", err)
+			}
+			continue
+		}
+
+		return readResponse(res)
+	}
+}
+
+func readResponse(res *http.Response) ([]byte, error) {
 	defer res.Body.Close()
 
-	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusUnauthorized && res.StatusCode != http.StatusBadRequest {
-		return nil, fmt.Errorf("can't do request: %w", fmt.Errorf("%w to %s: %d", ErrWrongStatusCode, res.Request.URL.String(), res.StatusCode))
-	} else if res.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("can't do request: %w", fmt.Errorf("%w to %s", Err401StatusCode, res.Request.URL.String()))
-	} else if res.StatusCode == http.StatusBadRequest {
-		return nil, fmt.Errorf("can't do request: %w", fmt.Errorf("%w to %s", Err400StatusCode, res.Request.URL.String()))
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+		return nil, fmt.Errorf("can't do request: %w", &StatusCodeError{URL: res.Request.URL.String(), StatusCode: res.StatusCode, Body: body})
 	}
 
 	body, err := io.ReadAll(res.Body)
@@ -50,7 +121,7 @@ func (hc *HTTPClient) Do(ctx context.Context, r *http.Request) ([]byte, error) {
 
 // CreateRequest return http.Request with given parameters. If you don't need some of the parameters then give nil.
 func (hc *HTTPClient) CreateRequest(ctx context.Context, httpMethod string, url string, header http.Header, body io.Reader, query url.Values) (*http.Request, error) {
-	req, err := http.NewRequest(httpMethod, url, body)
+	req, err := http.NewRequestWithContext(ctx, httpMethod, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrCantCreateRequest, err)
 	}
@@ -2,33 +2,97 @@ package postgres
 
 import (
 	"fmt"
-	"reflect"
 
 	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
-	"github.com/4aykovski/effective_mobile_test_task/pkg/tag"
 )
 
-func AddFilterToStmt(stmt string, args []interface{}, filterOptions filter.Options, model interface{}) (string, []interface{}) {
+// AddFilterToStmt appends WHERE clause built from filterOptions to stmt. Fields, operators and values are
+// validated against the filter schema of model, so invalid options are reported instead of being dropped.
+func AddFilterToStmt(stmt string, args []interface{}, filterOptions filter.Options, model interface{}) (string, []interface{}, error) {
 	stmt += fmt.Sprintf(" WHERE 1=1 ")
 
-	tags := map[string]string{}
-	carType := reflect.TypeOf(model)
-	for i := 0; i < carType.NumField(); i++ {
-		jsonTag := tag.ParseJsonTag(carType.Field(i).Tag.Get("json"))
-		dbTag := carType.Field(i).Tag.Get("db")
-		tags[jsonTag] = dbTag
+	schema, err := filter.SchemaOf(model)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get filter schema: %w", err)
 	}
 
 	for _, field := range filterOptions.Fields() {
-		if dbTag, ok := tags[field.Name]; ok {
-			op, err := filter.ParseOperator(field.Op)
-			if err != nil {
-				continue
-			}
-			stmt += fmt.Sprintf(" AND %s %s $%d", dbTag, op, len(args)+1)
-			args = append(args, field.Value)
+		cond, fieldArgs, err := comparisonToSQL(schema, field.Name, field.Op, field.Value, args)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to add filter: %w", err)
 		}
+		stmt += fmt.Sprintf(" AND %s", cond)
+		args = fieldArgs
 	}
 
-	return stmt, args
+	if expr := filterOptions.Expression(); expr != nil {
+		cond, exprArgs, err := expressionToSQL(expr, schema, args)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to add filter expression: %w", err)
+		}
+		stmt += fmt.Sprintf(" AND %s", cond)
+		args = exprArgs
+	}
+
+	return stmt, args, nil
+}
+
+func comparisonToSQL(schema *filter.Schema, name, op, value string, args []interface{}) (string, []interface{}, error) {
+	field, err := schema.Validate(name, op, value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sqlOp, err := filter.ParseOperator(op)
+	if err != nil {
+		return "", nil, fmt.Errorf("field %q: %w", name, err)
+	}
+
+	typedValue, err := field.ParseValue(value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args = append(args, typedValue)
+	return fmt.Sprintf("(%s %s $%d)", field.Column, sqlOp, len(args)), args, nil
+}
+
+// expressionToSQL renders expr as a parenthesized condition. Column names are taken only from the schema
+// and values are always passed as placeholders, so the expression can't inject SQL.
+func expressionToSQL(expr filter.Expr, schema *filter.Schema, args []interface{}) (string, []interface{}, error) {
+	switch e := expr.(type) {
+	case *filter.BinaryExpr:
+		var op string
+		switch e.Op {
+		case filter.LogicalAnd:
+			op = "AND"
+		case filter.LogicalOr:
+			op = "OR"
+		default:
+			return "", nil, fmt.Errorf("unknown logical operator %q", e.Op)
+		}
+
+		left, args, err := expressionToSQL(e.Left, schema, args)
+		if err != nil {
+			return "", nil, err
+		}
+
+		right, args, err := expressionToSQL(e.Right, schema, args)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return fmt.Sprintf("(%s %s %s)", left, op, right), args, nil
+	case *filter.NotExpr:
+		x, args, err := expressionToSQL(e.X, schema, args)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return fmt.Sprintf("(NOT %s)", x), args, nil
+	case *filter.Comparison:
+		return comparisonToSQL(schema, e.Field, e.Op, e.Value, args)
+	}
+
+	return "", nil, fmt.Errorf("unknown expression %T", expr)
 }
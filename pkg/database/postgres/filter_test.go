@@ -0,0 +1,147 @@
+package postgres_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+)
+
+type testCar struct {
+	RegNum string `db:"reg_number" json:"regNum" filter:"string"`
+	Mark   string `db:"mark" json:"mark" filter:"string"`
+	Year   int    `db:"year" json:"year" filter:"int"`
+}
+
+const baseStmt = "SELECT * FROM cars"
+
+type testField struct {
+	name, op, value string
+}
+
+func TestAddFilterToStmt(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []interface{}
+		fields   []testField
+		expr     string
+		wantStmt string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "no filters",
+			wantStmt: baseStmt + " WHERE 1=1 ",
+		},
+		{
+			name:     "field constrained twice",
+			fields:   []testField{{"year", "gt", "2000"}, {"year", "lt", "2010"}},
+			wantStmt: baseStmt + " WHERE 1=1  AND (year > $1) AND (year < $2)",
+			wantArgs: []interface{}{2000, 2010},
+		},
+		{
+			name:     "column is taken from db tag",
+			fields:   []testField{{"regNum", "eq", "X123XX150"}},
+			wantStmt: baseStmt + " WHERE 1=1  AND (reg_number = $1)",
+			wantArgs: []interface{}{"X123XX150"},
+		},
+		{
+			name:     "placeholders continue existing args",
+			args:     []interface{}{"first"},
+			fields:   []testField{{"mark", "eq", "Lada"}},
+			expr:     "year gte 2015",
+			wantStmt: baseStmt + " WHERE 1=1  AND (mark = $2) AND (year >= $3)",
+			wantArgs: []interface{}{"first", "Lada", 2015},
+		},
+		{
+			name:     "and binds tighter than or",
+			expr:     "mark eq Lada or mark eq Kia and year gt 2015",
+			wantStmt: baseStmt + " WHERE 1=1  AND ((mark = $1) OR ((mark = $2) AND (year > $3)))",
+			wantArgs: []interface{}{"Lada", "Kia", 2015},
+		},
+		{
+			name:     "parentheses",
+			expr:     "(mark eq Lada or mark eq Kia) and year gt 2015",
+			wantStmt: baseStmt + " WHERE 1=1  AND (((mark = $1) OR (mark = $2)) AND (year > $3))",
+			wantArgs: []interface{}{"Lada", "Kia", 2015},
+		},
+		{
+			name:     "not",
+			expr:     "not (mark neq Lada or year lte 2000)",
+			wantStmt: baseStmt + " WHERE 1=1  AND (NOT ((mark != $1) OR (year <= $2)))",
+			wantArgs: []interface{}{"Lada", 2000},
+		},
+		{
+			name:     "sql in expression value",
+			expr:     `mark eq "Lada') OR 1=1; DROP TABLE cars; --"`,
+			wantStmt: baseStmt + " WHERE 1=1  AND (mark = $1)",
+			wantArgs: []interface{}{"Lada') OR 1=1; DROP TABLE cars; --"},
+		},
+		{
+			name:     "sql in field value",
+			fields:   []testField{{"mark", "eq", "' OR '1'='1"}},
+			wantStmt: baseStmt + " WHERE 1=1  AND (mark = $1)",
+			wantArgs: []interface{}{"' OR '1'='1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, args, err := postgres.AddFilterToStmt(baseStmt, tt.args, newOptions(t, tt.fields, tt.expr), testCar{})
+			if err != nil {
+				t.Fatalf("AddFilterToStmt: %v", err)
+			}
+
+			if stmt != tt.wantStmt {
+				t.Errorf("stmt = %q, want %q", stmt, tt.wantStmt)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestAddFilterToStmtErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []testField
+		expr   string
+	}{
+		{name: "unknown field", fields: []testField{{"price", "eq", "1"}}},
+		{name: "unsupported operator", fields: []testField{{"mark", "gt", "Lada"}}},
+		{name: "invalid value", fields: []testField{{"year", "eq", "1; DROP TABLE cars"}}},
+		{name: "unknown field in expression", expr: "mark eq Lada or price lt 100"},
+		{name: "column name in expression", expr: "reg_number eq X123XX150"},
+		{name: "invalid value in expression", expr: "not year eq old"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := postgres.AddFilterToStmt(baseStmt, nil, newOptions(t, tt.fields, tt.expr), testCar{}); err == nil {
+				t.Error("AddFilterToStmt() error = nil, want error")
+			}
+		})
+	}
+}
+
+func newOptions(t *testing.T, fields []testField, expr string) filter.Options {
+	t.Helper()
+
+	options := filter.NewOptions()
+	for _, f := range fields {
+		if err := options.AddField(f.name, f.op, f.value, ""); err != nil {
+			t.Fatalf("AddField: %v", err)
+		}
+	}
+
+	if expr != "" {
+		parsed, err := filter.ParseExpression(expr)
+		if err != nil {
+			t.Fatalf("ParseExpression(%q): %v", expr, err)
+		}
+		options.SetExpression(parsed)
+	}
+
+	return options
+}
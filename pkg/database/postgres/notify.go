@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const listenerPingInterval = 90 * time.Second
+
+// Change is the payload sent by change notification triggers.
+type Change struct {
+	Table string `json:"table"`
+	Op    string `json:"op"`
+	ID    string `json:"id"`
+}
+
+func ParseChange(payload string) (Change, error) {
+	var change Change
+	if err := json.Unmarshal([]byte(payload), &change); err != nil {
+		return Change{}, fmt.Errorf("failed to parse change notification: %w", err)
+	}
+	return change, nil
+}
+
+// Notification is a message received on a channel. Reconnected notifications carry no payload and mean
+// that the connection was lost, so any notifications sent in the meantime were missed.
+type Notification struct {
+	Channel     string
+	Payload     string
+	Reconnected bool
+}
+
+// Notify sends payload to every session listening on channel.
+func (p *Postgres) Notify(ctx context.Context, channel, payload string) error {
+	if _, err := p.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, payload); err != nil {
+		return fmt.Errorf("failed to notify %s: %w", channel, err)
+	}
+	return nil
+}
+
+// Listener receives notifications on a dedicated connection and dispatches them to subscribers.
+// The connection is reestablished automatically when it is lost.
+type Listener struct {
+	log      *slog.Logger
+	listener *pq.Listener
+
+	mu          sync.RWMutex
+	subscribers map[string][]func(Notification)
+}
+
+func NewListener(log *slog.Logger, dsn string, minReconnectInterval, maxReconnectInterval time.Duration) *Listener {
+	log = log.With(slog.String("component", "postgres_listener"))
+
+	eventCallback := func(event pq.ListenerEventType, err error) {
+		switch event {
+		case pq.ListenerEventConnected:
+			log.Debug("listener connected")
+		case pq.ListenerEventDisconnected:
+			log.Error("listener disconnected", slog.Any("error", err))
+		case pq.ListenerEventReconnected:
+			log.Info("listener reconnected")
+		case pq.ListenerEventConnectionAttemptFailed:
+			log.Error("listener connection attempt failed", slog.Any("error", err))
+		}
+	}
+
+	return &Listener{
+		log:         log,
+		listener:    pq.NewListener(dsn, minReconnectInterval, maxReconnectInterval, eventCallback),
+		subscribers: make(map[string][]func(Notification)),
+	}
+}
+
+// Subscribe calls handler for every notification on channel. Handlers are called sequentially from Run,
+// so they must not block.
+func (l *Listener) Subscribe(channel string, handler func(Notification)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.subscribers[channel]; !ok {
+		if err := l.listener.Listen(channel); err != nil {
+			return fmt.Errorf("failed to listen %s: %w", channel, err)
+		}
+	}
+
+	l.subscribers[channel] = append(l.subscribers[channel], handler)
+	return nil
+}
+
+// Run dispatches notifications until ctx is cancelled and closes the listener afterwards.
+func (l *Listener) Run(ctx context.Context) {
+	defer l.listener.Close()
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n := <-l.listener.Notify:
+			if n == nil {
+				l.dispatchReconnected()
+				continue
+			}
+
+			l.dispatch(Notification{Channel: n.Channel, Payload: n.Extra})
+		case <-ticker.C:
+			// ping detects broken connections on idle channels
+			if err := l.listener.Ping(); err != nil {
+				l.log.Error("listener ping failed", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func (l *Listener) dispatch(n Notification) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, handler := range l.subscribers[n.Channel] {
+		handler(n)
+	}
+}
+
+func (l *Listener) dispatchReconnected() {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for channel, handlers := range l.subscribers {
+		for _, handler := range handlers {
+			handler(Notification{Channel: channel, Reconnected: true})
+		}
+	}
+}
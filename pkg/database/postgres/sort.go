@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+)
+
+// AddSortToStmt appends ORDER BY clause built from sortFields to stmt, columns are taken from the filter schema
+// of model. tieBreaker is a unique column appended last, so pages of sorted results don't overlap.
+func AddSortToStmt(stmt string, sortFields []filter.SortField, model interface{}, tieBreaker string) (string, error) {
+	schema, err := filter.SchemaOf(model)
+	if err != nil {
+		return "", fmt.Errorf("failed to get filter schema: %w", err)
+	}
+
+	order := make([]string, 0, len(sortFields)+1)
+	for _, sortField := range sortFields {
+		field, ok := schema.Field(sortField.Name)
+		if !ok {
+			return "", fmt.Errorf("failed to add sort: %w %q", filter.ErrUnknownField, sortField.Name)
+		}
+
+		if sortField.Desc {
+			order = append(order, field.Column+" DESC")
+		} else {
+			order = append(order, field.Column)
+		}
+	}
+	order = append(order, tieBreaker)
+
+	return stmt + " ORDER BY " + strings.Join(order, ", "), nil
+}
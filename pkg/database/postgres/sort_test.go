@@ -0,0 +1,65 @@
+package postgres_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/database/postgres"
+)
+
+func TestAddSortToStmt(t *testing.T) {
+	tests := []struct {
+		name       string
+		sortFields []filter.SortField
+		want       string
+	}{
+		{
+			name: "only tie breaker",
+			want: baseStmt + " ORDER BY reg_number",
+		},
+		{
+			name:       "ascending",
+			sortFields: []filter.SortField{{Name: "year"}},
+			want:       baseStmt + " ORDER BY year, reg_number",
+		},
+		{
+			name:       "descending and column from db tag",
+			sortFields: []filter.SortField{{Name: "year", Desc: true}, {Name: "regNum"}},
+			want:       baseStmt + " ORDER BY year DESC, reg_number, reg_number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := postgres.AddSortToStmt(baseStmt, tt.sortFields, testCar{}, "reg_number")
+			if err != nil {
+				t.Fatalf("AddSortToStmt: %v", err)
+			}
+
+			if stmt != tt.want {
+				t.Errorf("stmt = %q, want %q", stmt, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddSortToStmtUnknownField(t *testing.T) {
+	tests := []struct {
+		name      string
+		sortField filter.SortField
+	}{
+		{name: "unknown field", sortField: filter.SortField{Name: "price"}},
+		{name: "column name", sortField: filter.SortField{Name: "reg_number"}},
+		{name: "sql", sortField: filter.SortField{Name: "year; DROP TABLE cars"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := postgres.AddSortToStmt(baseStmt, []filter.SortField{tt.sortField}, testCar{}, "reg_number")
+			if !errors.Is(err, filter.ErrUnknownField) {
+				t.Errorf("AddSortToStmt() error = %v, want %v", err, filter.ErrUnknownField)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Package jwt verifies JSON Web Tokens signed with HS256 or RS256.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+)
+
+var (
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrExpiredToken         = errors.New("token is expired")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+)
+
+// Claims are registered claims and scopes. Scopes are taken either from space separated "scope" claim
+// or from "scopes" array.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+}
+
+// AllScopes returns scopes from both scope claims.
+func (c Claims) AllScopes() []string {
+	return append(strings.Fields(c.Scope), c.Scopes...)
+}
+
+// Audience is "aud" claim which is either a string or an array of strings.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+
+	return nil
+}
+
+type Config struct {
+	// HMACSecret enables HS256, RSAPublicKey enables RS256. Tokens signed with other algorithms are rejected.
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+	// Issuer and Audience are checked if set.
+	Issuer   string
+	Audience string
+	// Leeway is allowed clock skew for exp and nbf.
+	Leeway time.Duration
+}
+
+type Verifier struct {
+	cfg Config
+	now func() time.Time
+}
+
+func NewVerifier(cfg Config) *Verifier {
+	return &Verifier{
+		cfg: cfg,
+		now: time.Now,
+	}
+}
+
+// Verify checks signature and time claims of token and returns its claims. Tokens without exp are rejected.
+func (v *Verifier) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, fmt.Errorf("%w: can't decode header: %w", ErrInvalidToken, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: can't decode signature: %w", ErrInvalidToken, err)
+	}
+
+	if err = v.verifySignature(header.Alg, parts[0]+"."+parts[1], signature); err != nil {
+		return Claims{}, err
+	}
+
+	var claims Claims
+	if err = decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, fmt.Errorf("%w: can't decode claims: %w", ErrInvalidToken, err)
+	}
+
+	if err = v.validateClaims(claims); err != nil {
+		return Claims{}, err
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) verifySignature(alg, signed string, signature []byte) error {
+	switch {
+	case alg == AlgHS256 && v.cfg.HMACSecret != nil:
+		mac := hmac.New(sha256.New, v.cfg.HMACSecret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	case alg == AlgRS256 && v.cfg.RSAPublicKey != nil:
+		digest := sha256.Sum256([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(v.cfg.RSAPublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidToken)
+		}
+	default:
+		return fmt.Errorf("%w: %w %q", ErrInvalidToken, ErrUnsupportedAlgorithm, alg)
+	}
+
+	return nil
+}
+
+func (v *Verifier) validateClaims(claims Claims) error {
+	now := v.now()
+
+	if claims.ExpiresAt == 0 {
+		return fmt.Errorf("%w: exp claim is required", ErrInvalidToken)
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(v.cfg.Leeway)) {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, ErrExpiredToken)
+	}
+	if claims.NotBefore != 0 && now.Before(time.Unix(claims.NotBefore, 0).Add(-v.cfg.Leeway)) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+
+	if v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+	if v.cfg.Audience != "" && !slices.Contains(claims.Audience, v.cfg.Audience) {
+		return fmt.Errorf("%w: token is not issued for %q", ErrInvalidToken, v.cfg.Audience)
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+// ParseRSAPublicKey parses PEM encoded PKIX or PKCS #1 public key or certificate.
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var key any
+	switch block.Type {
+	case "PUBLIC KEY":
+		k, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse public key: %w", err)
+		}
+		key = k
+	case "RSA PUBLIC KEY":
+		k, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse public key: %w", err)
+		}
+		key = k
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse certificate: %w", err)
+		}
+		key = cert.PublicKey
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not RSA")
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,391 @@
+package jwt_test
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/jwt"
+)
+
+var hmacSecret = []byte("secret")
+
+func newRSAKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("MarshalPKIXPublicKey: %v", err)
+	}
+
+	return key, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// signHS256 signs token with alg in header using HMAC-SHA256, so tokens with wrong alg can be made too.
+func signHS256(t *testing.T, alg string, secret []byte, claims any) string {
+	t.Helper()
+
+	signed := encodeSegment(t, map[string]string{"alg": alg, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims any) string {
+	t.Helper()
+
+	signed := encodeSegment(t, map[string]string{"alg": jwt.AlgRS256, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("SignPKCS1v15: %v", err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerifySignature(t *testing.T) {
+	rsaKey, publicPEM := newRSAKey(t)
+	otherKey, _ := newRSAKey(t)
+
+	claims := map[string]any{"sub": "user", "exp": time.Now().Add(time.Hour).Unix()}
+	payload := encodeSegment(t, claims)
+	tampered := func(token string) string {
+		parts := strings.Split(token, ".")
+		parts[1] = encodeSegment(t, map[string]any{"sub": "admin", "exp": claims["exp"]})
+		return strings.Join(parts, ".")
+	}
+
+	hmacOnly := jwt.Config{HMACSecret: hmacSecret}
+	rsaOnly := jwt.Config{RSAPublicKey: &rsaKey.PublicKey}
+	both := jwt.Config{HMACSecret: hmacSecret, RSAPublicKey: &rsaKey.PublicKey}
+
+	tests := []struct {
+		name    string
+		cfg     jwt.Config
+		token   string
+		wantErr error
+	}{
+		{
+			name:  "HS256",
+			cfg:   hmacOnly,
+			token: signHS256(t, jwt.AlgHS256, hmacSecret, claims),
+		},
+		{
+			name:  "RS256",
+			cfg:   rsaOnly,
+			token: signRS256(t, rsaKey, claims),
+		},
+		{
+			name:  "RS256 when both are enabled",
+			cfg:   both,
+			token: signRS256(t, rsaKey, claims),
+		},
+		{
+			name:    "HS256 with wrong secret",
+			cfg:     hmacOnly,
+			token:   signHS256(t, jwt.AlgHS256, []byte("other"), claims),
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:    "RS256 with wrong key",
+			cfg:     rsaOnly,
+			token:   signRS256(t, otherKey, claims),
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:    "tampered HS256 claims",
+			cfg:     hmacOnly,
+			token:   tampered(signHS256(t, jwt.AlgHS256, hmacSecret, claims)),
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:    "tampered RS256 claims",
+			cfg:     rsaOnly,
+			token:   tampered(signRS256(t, rsaKey, claims)),
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:    "HS256 signed with RSA public key",
+			cfg:     rsaOnly,
+			token:   signHS256(t, jwt.AlgHS256, publicPEM, claims),
+			wantErr: jwt.ErrUnsupportedAlgorithm,
+		},
+		{
+			name:    "HS256 signed with RSA public key when both are enabled",
+			cfg:     both,
+			token:   signHS256(t, jwt.AlgHS256, publicPEM, claims),
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:    "RS256 when only HS256 is enabled",
+			cfg:     hmacOnly,
+			token:   signRS256(t, rsaKey, claims),
+			wantErr: jwt.ErrUnsupportedAlgorithm,
+		},
+		{
+			name:    "alg none",
+			cfg:     both,
+			token:   encodeSegment(t, map[string]string{"alg": "none"}) + "." + payload + ".",
+			wantErr: jwt.ErrUnsupportedAlgorithm,
+		},
+		{
+			name:    "alg is case sensitive",
+			cfg:     hmacOnly,
+			token:   signHS256(t, "hs256", hmacSecret, claims),
+			wantErr: jwt.ErrUnsupportedAlgorithm,
+		},
+		{
+			name:    "unknown alg",
+			cfg:     both,
+			token:   signHS256(t, "HS512", hmacSecret, claims),
+			wantErr: jwt.ErrUnsupportedAlgorithm,
+		},
+		{
+			name:    "missing alg",
+			cfg:     both,
+			token:   encodeSegment(t, map[string]string{"typ": "JWT"}) + "." + payload + ".c2ln",
+			wantErr: jwt.ErrUnsupportedAlgorithm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.NewVerifier(tt.cfg).Verify(tt.token)
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	header := encodeSegment(t, map[string]string{"alg": jwt.AlgHS256})
+	claims := encodeSegment(t, map[string]any{"exp": time.Now().Add(time.Hour).Unix()})
+	valid := signHS256(t, jwt.AlgHS256, hmacSecret, map[string]any{"exp": time.Now().Add(time.Hour).Unix()})
+	signature := valid[strings.LastIndex(valid, ".")+1:]
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty", token: ""},
+		{name: "two segments", token: header + "." + claims},
+		{name: "four segments", token: valid + ".x"},
+		{name: "header isn't base64", token: "!!!." + claims + "." + signature},
+		{name: "header isn't JSON", token: base64.RawURLEncoding.EncodeToString([]byte("alg")) + "." + claims + "." + signature},
+		{name: "signature isn't base64", token: header + "." + claims + ".!!!"},
+		{name: "claims aren't JSON", token: signRaw(header, base64.RawURLEncoding.EncodeToString([]byte("{")))},
+		{name: "exp isn't number", token: signRaw(header, encodeSegment(t, map[string]any{"exp": "tomorrow"}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.NewVerifier(jwt.Config{HMACSecret: hmacSecret}).Verify(tt.token)
+			checkError(t, err, jwt.ErrInvalidToken)
+		})
+	}
+}
+
+func TestVerifyClaims(t *testing.T) {
+	now := time.Now()
+	hour := now.Add(time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		cfg     jwt.Config
+		claims  map[string]any
+		wantErr error
+	}{
+		{
+			name:   "valid",
+			claims: map[string]any{"exp": hour},
+		},
+		{
+			name:    "missing exp",
+			claims:  map[string]any{"sub": "user"},
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:    "expired",
+			claims:  map[string]any{"exp": now.Add(-time.Minute).Unix()},
+			wantErr: jwt.ErrExpiredToken,
+		},
+		{
+			name:   "expired within leeway",
+			cfg:    jwt.Config{Leeway: time.Minute},
+			claims: map[string]any{"exp": now.Add(-30 * time.Second).Unix()},
+		},
+		{
+			name:    "expired beyond leeway",
+			cfg:     jwt.Config{Leeway: time.Minute},
+			claims:  map[string]any{"exp": now.Add(-2 * time.Minute).Unix()},
+			wantErr: jwt.ErrExpiredToken,
+		},
+		{
+			name:    "not valid yet",
+			claims:  map[string]any{"exp": hour, "nbf": now.Add(time.Minute).Unix()},
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:   "not valid yet within leeway",
+			cfg:    jwt.Config{Leeway: time.Minute},
+			claims: map[string]any{"exp": hour, "nbf": now.Add(30 * time.Second).Unix()},
+		},
+		{
+			name:   "already valid",
+			claims: map[string]any{"exp": hour, "nbf": now.Add(-time.Minute).Unix()},
+		},
+		{
+			name:   "issuer",
+			cfg:    jwt.Config{Issuer: "auth"},
+			claims: map[string]any{"exp": hour, "iss": "auth"},
+		},
+		{
+			name:    "wrong issuer",
+			cfg:     jwt.Config{Issuer: "auth"},
+			claims:  map[string]any{"exp": hour, "iss": "other"},
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:    "missing issuer",
+			cfg:     jwt.Config{Issuer: "auth"},
+			claims:  map[string]any{"exp": hour},
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:   "issuer isn't checked if not configured",
+			claims: map[string]any{"exp": hour, "iss": "other"},
+		},
+		{
+			name:   "audience string",
+			cfg:    jwt.Config{Audience: "cars"},
+			claims: map[string]any{"exp": hour, "aud": "cars"},
+		},
+		{
+			name:   "audience array",
+			cfg:    jwt.Config{Audience: "cars"},
+			claims: map[string]any{"exp": hour, "aud": []string{"owners", "cars"}},
+		},
+		{
+			name:    "wrong audience",
+			cfg:     jwt.Config{Audience: "cars"},
+			claims:  map[string]any{"exp": hour, "aud": []string{"owners"}},
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:    "missing audience",
+			cfg:     jwt.Config{Audience: "cars"},
+			claims:  map[string]any{"exp": hour},
+			wantErr: jwt.ErrInvalidToken,
+		},
+		{
+			name:    "audience isn't string",
+			claims:  map[string]any{"exp": hour, "aud": 1},
+			wantErr: jwt.ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.HMACSecret = hmacSecret
+
+			_, err := jwt.NewVerifier(tt.cfg).Verify(signHS256(t, jwt.AlgHS256, hmacSecret, tt.claims))
+			checkError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifyReturnsClaims(t *testing.T) {
+	exp := time.Now().Add(time.Hour).Unix()
+	token := signHS256(t, jwt.AlgHS256, hmacSecret, map[string]any{
+		"sub":    "user",
+		"exp":    exp,
+		"aud":    "cars",
+		"scope":  "cars:read cars:write",
+		"scopes": []string{"owners:write"},
+	})
+
+	claims, err := jwt.NewVerifier(jwt.Config{HMACSecret: hmacSecret}).Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	if claims.Subject != "user" || claims.ExpiresAt != exp || !slices.Equal(claims.Audience, jwt.Audience{"cars"}) {
+		t.Errorf("claims = %+v", claims)
+	}
+	if got, want := claims.AllScopes(), []string{"cars:read", "cars:write", "owners:write"}; !slices.Equal(got, want) {
+		t.Errorf("scopes = %v, want %v", got, want)
+	}
+}
+
+func TestParseRSAPublicKey(t *testing.T) {
+	key, pkixPEM := newRSAKey(t)
+	pkcs1PEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})
+
+	for name, data := range map[string][]byte{"PKIX": pkixPEM, "PKCS #1": pkcs1PEM} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := jwt.ParseRSAPublicKey(data)
+			if err != nil {
+				t.Fatalf("ParseRSAPublicKey: %v", err)
+			}
+			if !parsed.Equal(&key.PublicKey) {
+				t.Errorf("parsed key doesn't match")
+			}
+		})
+	}
+
+	if _, err := jwt.ParseRSAPublicKey([]byte("not a key")); err == nil {
+		t.Errorf("ParseRSAPublicKey of garbage succeeded")
+	}
+}
+
+func checkError(t *testing.T, err, wantErr error) {
+	t.Helper()
+
+	if wantErr == nil {
+		if err != nil {
+			t.Fatalf("Verify: %v", err)
+		}
+		return
+	}
+
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("err = %v, want %v", err, wantErr)
+	}
+	if !errors.Is(err, jwt.ErrInvalidToken) {
+		t.Errorf("err = %v, want it to wrap %v", err, jwt.ErrInvalidToken)
+	}
+}
+
+// signRaw signs already encoded segments with HS256.
+func signRaw(header, claims string) string {
+	mac := hmac.New(sha256.New, hmacSecret)
+	mac.Write([]byte(header + "." + claims))
+	return header + "." + claims + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -1,33 +1,34 @@
 package mapper
 
 import (
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/carinfoservice"
 	"github.com/4aykovski/effective_mobile_test_task/internal/service/carservice"
 	"github.com/4aykovski/effective_mobile_test_task/internal/service/ownerservice"
-	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
 )
 
-func CarInfoIntoCarAndOwner(carInfos map[string]carinfo.CarInfo) ([]carservice.AddNewCarInput, []ownerservice.AddNewOwnerInput) {
+func CarInfoIntoCarAndOwner(results []carinfoservice.Result) ([]carservice.AddNewCarInput, []ownerservice.AddNewOwnerInput) {
 	var cars []carservice.AddNewCarInput
-	for regNumber, carInfo := range carInfos {
-		valid := true
-		if carInfo == (carinfo.CarInfo{}) {
-			valid = false
-		}
+	var owners []ownerservice.AddNewOwnerInput
+	for _, result := range results {
+		carInfo := result.Info
+		valid := result.Err == nil
 
 		car := carservice.AddNewCarInput{
-			RegistrationNumber: regNumber,
+			RegistrationNumber: result.RegNumber,
 			Mark:               carInfo.Mark,
 			Model:              carInfo.Model,
 			Year:               carInfo.Year,
 			OwnerName:          carInfo.Owner.Name,
 			OwnerSurname:       carInfo.Owner.Surname,
+			Source:             result.Provider,
 			Valid:              valid,
 		}
 		cars = append(cars, car)
-	}
 
-	var owners []ownerservice.AddNewOwnerInput
-	for _, carInfo := range carInfos {
+		if !valid {
+			continue
+		}
+
 		owner := ownerservice.AddNewOwnerInput{
 			Name:       carInfo.Owner.Name,
 			Surname:    carInfo.Owner.Surname,
@@ -0,0 +1,16 @@
+package ratelimit
+
+import "time"
+
+// SetNow replaces the clock of l.
+func (l *Limiter) SetNow(now func() time.Time) {
+	l.now = now
+}
+
+// Len returns number of kept buckets.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.buckets)
+}
@@ -0,0 +1,112 @@
+// Package ratelimit limits request rate of clients with token buckets.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of Allow.
+type Result struct {
+	Allowed bool
+	// Limit is bucket size, i.e. the number of requests allowed in a burst.
+	Limit     int
+	Remaining int
+	// RetryAfter is time until the next request is allowed, zero for allowed requests.
+	RetryAfter time.Duration
+	// Reset is time until the bucket is full again.
+	Reset time.Duration
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// Limiter keeps a token bucket per key. Buckets are refilled with rate tokens per second up to burst tokens.
+// It is safe for concurrent use.
+type Limiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   int
+	buckets map[string]*bucket
+	maxKeys int
+	sweepAt int
+	now     func() time.Time
+}
+
+// New returns limiter allowing rate requests per second with bursts of burst requests, rate must be positive.
+// When maxKeys buckets are kept, full buckets are dropped, as their keys would start with a full bucket anyway.
+// Buckets that still owe tokens are never dropped, so changing keys doesn't reset the limit, and more than
+// maxKeys buckets are kept while they refill.
+func New(rate float64, burst int, maxKeys int) *Limiter {
+	return &Limiter{
+		rate:    rate,
+		burst:   max(burst, 1),
+		buckets: make(map[string]*bucket),
+		maxKeys: maxKeys,
+		sweepAt: maxKeys,
+		now:     time.Now,
+	}
+}
+
+// Window is time needed to refill empty bucket.
+func (l *Limiter) Window() time.Duration {
+	return l.duration(float64(l.burst))
+}
+
+// Allow takes a token from the bucket of key if there is one.
+func (l *Limiter) Allow(key string) Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.sweepAt {
+			l.sweep(now)
+		}
+
+		b = &bucket{tokens: float64(l.burst), updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = l.tokens(b, now)
+	b.updatedAt = now
+
+	res := Result{Limit: l.burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = l.duration(1 - b.tokens)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = l.duration(float64(l.burst) - b.tokens)
+
+	return res
+}
+
+// sweep drops full buckets. The next sweep happens when the number of buckets doubles, so sweeping stays cheap
+// when most buckets owe tokens.
+func (l *Limiter) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if l.tokens(b, now) >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.sweepAt = max(l.maxKeys, 2*len(l.buckets))
+}
+
+// tokens returns tokens of b refilled up to now.
+func (l *Limiter) tokens(b *bucket, now time.Time) float64 {
+	return math.Min(float64(l.burst), b.tokens+now.Sub(b.updatedAt).Seconds()*l.rate)
+}
+
+func (l *Limiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
@@ -0,0 +1,146 @@
+package ratelimit_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/ratelimit"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newLimiter(rate float64, burst, maxKeys int) (*ratelimit.Limiter, *clock) {
+	c := &clock{now: time.Date(2024, time.April, 21, 12, 0, 0, 0, time.UTC)}
+	l := ratelimit.New(rate, burst, maxKeys)
+	l.SetNow(c.Now)
+	return l, c
+}
+
+func TestAllow(t *testing.T) {
+	l, c := newLimiter(2, 3, 100)
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		want    ratelimit.Result
+	}{
+		{
+			name: "first request",
+			want: ratelimit.Result{Allowed: true, Limit: 3, Remaining: 2, Reset: 500 * time.Millisecond},
+		},
+		{
+			name: "second request of burst",
+			want: ratelimit.Result{Allowed: true, Limit: 3, Remaining: 1, Reset: time.Second},
+		},
+		{
+			name: "last request of burst",
+			want: ratelimit.Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 1500 * time.Millisecond},
+		},
+		{
+			name: "burst is used up",
+			want: ratelimit.Result{Limit: 3, RetryAfter: 500 * time.Millisecond, Reset: 1500 * time.Millisecond},
+		},
+		{
+			name:    "half of token refilled",
+			advance: 250 * time.Millisecond,
+			want:    ratelimit.Result{Limit: 3, RetryAfter: 250 * time.Millisecond, Reset: 1250 * time.Millisecond},
+		},
+		{
+			name:    "token refilled",
+			advance: 250 * time.Millisecond,
+			want:    ratelimit.Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 1500 * time.Millisecond},
+		},
+		{
+			name:    "refill is capped by burst",
+			advance: time.Hour,
+			want:    ratelimit.Result{Allowed: true, Limit: 3, Remaining: 2, Reset: 500 * time.Millisecond},
+		},
+	}
+
+	for _, step := range steps {
+		c.Advance(step.advance)
+
+		if got := l.Allow("client"); got != step.want {
+			t.Fatalf("%s: Allow() = %+v, want %+v", step.name, got, step.want)
+		}
+	}
+}
+
+func TestAllowSlowRate(t *testing.T) {
+	l, c := newLimiter(0.5, 1, 100)
+
+	if res := l.Allow("client"); !res.Allowed {
+		t.Fatalf("first request isn't allowed: %+v", res)
+	}
+
+	res := l.Allow("client")
+	if res.Allowed || res.RetryAfter != 2*time.Second {
+		t.Fatalf("Allow() = %+v, want denied with retry after 2s", res)
+	}
+
+	c.Advance(2 * time.Second)
+	if res = l.Allow("client"); !res.Allowed {
+		t.Errorf("request after retry after isn't allowed: %+v", res)
+	}
+
+	if got, want := l.Window(), 2*time.Second; got != want {
+		t.Errorf("Window() = %v, want %v", got, want)
+	}
+}
+
+func TestAllowKeysAreIndependent(t *testing.T) {
+	l, _ := newLimiter(1, 1, 100)
+
+	if res := l.Allow("a"); !res.Allowed {
+		t.Fatalf("a isn't allowed: %+v", res)
+	}
+	if res := l.Allow("a"); res.Allowed {
+		t.Fatalf("a is allowed over burst: %+v", res)
+	}
+	if res := l.Allow("b"); !res.Allowed {
+		t.Errorf("b isn't allowed after a used its burst: %+v", res)
+	}
+}
+
+func TestAllowDoesNotForgetIndebtedKeys(t *testing.T) {
+	l, c := newLimiter(1, 2, 2)
+
+	l.Allow("attacker")
+	l.Allow("attacker")
+
+	// rotating keys fills the limiter over maxKeys
+	for i := range 10 {
+		l.Allow(fmt.Sprintf("key-%d", i))
+	}
+
+	if res := l.Allow("attacker"); res.Allowed {
+		t.Fatalf("attacker is allowed after rotating keys: %+v", res)
+	}
+	if got := l.Len(); got != 11 {
+		t.Errorf("Len() = %d, want 11", got)
+	}
+
+	// refilled buckets are dropped when a new key comes
+	c.Advance(time.Minute)
+	for i := range 40 {
+		l.Allow(fmt.Sprintf("other-key-%d", i))
+	}
+
+	if got := l.Len(); got > 40 {
+		t.Errorf("Len() = %d, want refilled buckets dropped", got)
+	}
+	if res := l.Allow("attacker"); !res.Allowed || res.Remaining != 1 {
+		t.Errorf("attacker after refill = %+v, want allowed with full bucket", res)
+	}
+}
@@ -7,11 +7,32 @@ type Response struct {
 	Error  string `json:"error,omitempty"`
 }
 
+// Violation is a mismatch between the request and the API spec.
+type Violation struct {
+	// In is location of the value: path, query or body.
+	In string `json:"in"`
+	// Name is parameter name or path to the body field, e.g. regNumber[0]. It is empty for the whole body.
+	Name    string `json:"name,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse lists every mismatch between the request and the API spec.
+type ValidationErrorResponse struct {
+	Response
+	Violations []Violation `json:"violations,omitempty"`
+}
+
 const (
 	statusOK                   = "OK"
 	statusError                = "Error"
 	internalServerErrorMessage = "Internal server error"
 	badRequestErrorMessage     = "Bad request"
+	unavailableErrorMessage    = "Service unavailable"
+	unauthorizedErrorMessage   = "Unauthorized"
+	forbiddenErrorMessage      = "Forbidden"
+	tooManyRequestsMessage     = "Too many requests"
+	requestTooLargeMessage     = "Request body too large"
+	invalidRequestMessage      = "request doesn't match api spec"
 )
 
 func OK() Response {
@@ -24,6 +45,33 @@ func InternalError() Response {
 	return Error(internalServerErrorMessage)
 }
 
+func ServiceUnavailable(msg string) Response {
+	return Error(fmt.Sprintf("%s: %s", unavailableErrorMessage, msg))
+}
+
+func Unauthorized(msg string) Response {
+	return Error(fmt.Sprintf("%s: %s", unauthorizedErrorMessage, msg))
+}
+
+func Forbidden(msg string) Response {
+	return Error(fmt.Sprintf("%s: %s", forbiddenErrorMessage, msg))
+}
+
+func RequestTooLarge() Response {
+	return Error(requestTooLargeMessage)
+}
+
+func TooManyRequests() Response {
+	return Error(tooManyRequestsMessage)
+}
+
+func ValidationError(violations []Violation) ValidationErrorResponse {
+	return ValidationErrorResponse{
+		Response:   BadRequest(invalidRequestMessage),
+		Violations: violations,
+	}
+}
+
 func BadRequest(msg string) Response {
 	return Error(fmt.Sprintf("%s: %s", badRequestErrorMessage, msg))
 }
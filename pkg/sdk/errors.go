@@ -0,0 +1,63 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client"
+)
+
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrTooManyRequests    = errors.New("too many requests")
+	ErrInternal           = errors.New("internal server error")
+	ErrServiceUnavailable = errors.New("service unavailable")
+)
+
+// Error is an unsuccessful API response. It matches one of the errors above depending on the status code.
+type Error struct {
+	StatusCode int
+	// Message is the error field of the response envelope.
+	Message string
+}
+
+func newError(err *client.StatusCodeError) *Error {
+	var res struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(err.Body, &res) != nil || res.Error == "" {
+		res.Error = http.StatusText(err.StatusCode)
+	}
+
+	return &Error{
+		StatusCode: err.StatusCode,
+		Message:    res.Error,
+	}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrTooManyRequests:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrInternal:
+		return e.StatusCode == http.StatusInternalServerError
+	case ErrServiceUnavailable:
+		return e.StatusCode == http.StatusServiceUnavailable
+	default:
+		return false
+	}
+}
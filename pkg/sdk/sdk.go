@@ -0,0 +1,185 @@
+// Package sdk is a client of the cars catalog API.
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client"
+)
+
+const carsMethod = "/cars"
+
+type Car struct {
+	RegNumber    string `json:"regNumber"`
+	Mark         string `json:"mark"`
+	Model        string `json:"model"`
+	Year         int    `json:"year,omitempty"`
+	OwnerName    string `json:"ownerName"`
+	OwnerSurname string `json:"ownerSurname"`
+	Source       string `json:"source,omitempty"`
+}
+
+type Client struct {
+	httpClient *client.HTTPClient
+}
+
+// New returns client of the API served by httpClient, its base path must point to the API version, e.g. /api/v1.
+func New(httpClient *client.HTTPClient) *Client {
+	return &Client{
+		httpClient: httpClient,
+	}
+}
+
+type AddCarsInput struct {
+	RegNumbers []string `json:"regNumber"`
+	// BypassCache makes the service ask the car info API even if the answer is cached.
+	BypassCache bool `json:"bypassCache,omitempty"`
+}
+
+// AddCars adds cars by registration numbers and returns status of every number, e.g. "valid" or "not_found".
+func (c *Client) AddCars(ctx context.Context, input AddCarsInput) (map[string]string, error) {
+	var res struct {
+		ProcessedCars map[string]string `json:"processed_cars"`
+	}
+	if err := c.do(ctx, http.MethodPost, c.carsURL(""), nil, input, &res); err != nil {
+		return nil, fmt.Errorf("can't add cars: %w", err)
+	}
+
+	return res.ProcessedCars, nil
+}
+
+type ListCarsOptions struct {
+	// Limit is maximum number of cars, zero means no limit.
+	Limit  int
+	Offset int
+	// Fields filter cars by field, every value is either plain value or operator:value, e.g. "gt:2010".
+	// Several values of one field must all match.
+	Fields map[string][]string
+	// Filter is boolean filter expression, e.g. "(mark eq Lada or mark eq Kia) and year gt 2015".
+	Filter string
+	// Sort is comma separated fields prefixed with - for descending order, e.g. "-year,mark". Cars are sorted
+	// by the server before pagination, by registration number if Sort is empty.
+	Sort string
+}
+
+func (o ListCarsOptions) query() url.Values {
+	q := url.Values{}
+	if o.Limit > 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+
+	names := make([]string, 0, len(o.Fields))
+	for name := range o.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range o.Fields[name] {
+			q.Add(name, value)
+		}
+	}
+
+	if o.Filter != "" {
+		q.Set("filter", o.Filter)
+	}
+	if o.Sort != "" {
+		q.Set("sort", o.Sort)
+	}
+
+	return q
+}
+
+func (c *Client) ListCars(ctx context.Context, opts ListCarsOptions) ([]Car, error) {
+	var res struct {
+		Cars []Car `json:"cars"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.carsURL(""), opts.query(), nil, &res); err != nil {
+		return nil, fmt.Errorf("can't list cars: %w", err)
+	}
+
+	return res.Cars, nil
+}
+
+// UpdateCarInput holds fields to change, empty fields are left as they are.
+type UpdateCarInput struct {
+	Mark         string `json:"mark,omitempty"`
+	Model        string `json:"model,omitempty"`
+	Year         int    `json:"year,omitempty"`
+	OwnerName    string `json:"ownerName,omitempty"`
+	OwnerSurname string `json:"ownerSurname,omitempty"`
+}
+
+func (c *Client) UpdateCar(ctx context.Context, regNumber string, input UpdateCarInput) error {
+	if err := c.do(ctx, http.MethodPut, c.carsURL(regNumber), nil, input, nil); err != nil {
+		return fmt.Errorf("can't update car: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) DeleteCar(ctx context.Context, regNumber string) error {
+	if err := c.do(ctx, http.MethodDelete, c.carsURL(regNumber), nil, nil, nil); err != nil {
+		return fmt.Errorf("can't delete car: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Client) carsURL(regNumber string) string {
+	u := c.httpClient.GetUlrWithMethods(carsMethod)
+	if regNumber != "" {
+		// escaped, so slashes in the number can't lead to another path
+		u.RawPath = u.EscapedPath() + "/" + url.PathEscape(regNumber)
+		u.Path += "/" + regNumber
+	}
+	return u.String()
+}
+
+func (c *Client) do(ctx context.Context, method, u string, query url.Values, in, out any) error {
+	var header http.Header
+	var body io.Reader
+	if in != nil {
+		raw, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("can't marshal request: %w", err)
+		}
+		body = bytes.NewReader(raw)
+		header = http.Header{"Content-Type": []string{"application/json"}}
+	}
+
+	req, err := c.httpClient.CreateRequest(ctx, method, u, header, body, query)
+	if err != nil {
+		return err
+	}
+
+	res, err := c.httpClient.Do(ctx, req)
+	if err != nil {
+		var statusErr *client.StatusCodeError
+		if errors.As(err, &statusErr) {
+			return newError(statusErr)
+		}
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err = json.Unmarshal(res, out); err != nil {
+		return fmt.Errorf("can't decode response: %w", err)
+	}
+
+	return nil
+}
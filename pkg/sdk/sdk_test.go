@@ -0,0 +1,412 @@
+package sdk_test
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/4aykovski/effective_mobile_test_task/docs"
+	"github.com/4aykovski/effective_mobile_test_task/internal/model"
+	v1 "github.com/4aykovski/effective_mobile_test_task/internal/net/v1"
+	"github.com/4aykovski/effective_mobile_test_task/internal/repository"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/carinfoservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/carservice"
+	"github.com/4aykovski/effective_mobile_test_task/internal/service/ownerservice"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/filter"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/api/openapi"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/client/carinfo"
+	"github.com/4aykovski/effective_mobile_test_task/pkg/sdk"
+)
+
+type carRepository struct {
+	mu   sync.Mutex
+	cars map[string]model.Car
+}
+
+func (r *carRepository) InsertCar(ctx context.Context, car model.Car) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.cars[car.RegistrationNumber]; ok {
+		return repository.ErrCarExists
+	}
+	r.cars[car.RegistrationNumber] = car
+	return nil
+}
+
+func (r *carRepository) DeleteCar(ctx context.Context, regNumber string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.cars[regNumber]; !ok {
+		return repository.ErrCarNotFound
+	}
+	delete(r.cars, regNumber)
+	return nil
+}
+
+func (r *carRepository) UpdateCar(ctx context.Context, car model.Car) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.cars[car.RegistrationNumber]; !ok {
+		return repository.ErrCarNotFound
+	}
+	r.cars[car.RegistrationNumber] = car
+	return nil
+}
+
+func (r *carRepository) GetCar(ctx context.Context, regNumber string) (model.Car, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	car, ok := r.cars[regNumber]
+	if !ok {
+		return model.Car{}, repository.ErrCarNotFound
+	}
+	return car, nil
+}
+
+func (r *carRepository) GetCars(ctx context.Context, limit, offset int, filterOptions filter.Options, sortFields []filter.SortField) ([]model.Car, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	schema := filter.MustSchemaOf(model.Car{})
+	var cars []model.Car
+	for _, car := range r.cars {
+		ok, err := schema.Match(filterOptions, car)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			cars = append(cars, car)
+		}
+	}
+	slices.SortFunc(cars, func(a, b model.Car) int {
+		for _, field := range sortFields {
+			c := compareCars(a, b, field.Name)
+			if field.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return strings.Compare(a.RegistrationNumber, b.RegistrationNumber)
+	})
+
+	cars = cars[min(offset, len(cars)):]
+	if limit >= 0 {
+		cars = cars[:min(limit, len(cars))]
+	}
+	return cars, nil
+}
+
+// compareCars supports only fields used in tests.
+func compareCars(a, b model.Car, field string) int {
+	switch field {
+	case "mark":
+		return strings.Compare(a.Mark, b.Mark)
+	case "year":
+		return cmp.Compare(a.Year, b.Year)
+	}
+	return 0
+}
+
+type ownerRepository struct {
+	mu     sync.Mutex
+	owners map[string]model.Owner
+}
+
+func (r *ownerRepository) InsertOwner(ctx context.Context, owner model.Owner) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := owner.Name + " " + owner.Surname
+	if _, ok := r.owners[key]; ok {
+		return repository.ErrOwnerExists
+	}
+	r.owners[key] = owner
+	return nil
+}
+
+type carInfoProvider struct {
+	cars map[string]carinfo.CarInfo
+	err  error
+}
+
+func (p *carInfoProvider) GetCarInfoByRegNumber(ctx context.Context, regNumber string) (carinfo.CarInfo, error) {
+	if p.err != nil {
+		return carinfo.CarInfo{}, p.err
+	}
+
+	info, ok := p.cars[regNumber]
+	if !ok {
+		return carinfo.CarInfo{}, fmt.Errorf("%w: %s", carinfo.ErrNotFound, regNumber)
+	}
+	return info, nil
+}
+
+var knownCars = map[string]carinfo.CarInfo{
+	"A111AA111": {RegNumber: "A111AA111", Mark: "Lada", Model: "Vesta", Year: 2020, Owner: carinfo.Owner{Name: "Ivan", Surname: "Ivanov"}},
+	"B222BB222": {RegNumber: "B222BB222", Mark: "Kia", Model: "Rio", Year: 2012, Owner: carinfo.Owner{Name: "Petr", Surname: "Petrov"}},
+	"C333CC333": {RegNumber: "C333CC333", Mark: "Lada", Model: "Niva", Year: 2005, Owner: carinfo.Owner{Name: "Ivan", Surname: "Ivanov"}},
+}
+
+func newTestClient(t *testing.T, provider *carInfoProvider) *sdk.Client {
+	t.Helper()
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	carService := carservice.NewCarService(&carRepository{cars: make(map[string]model.Car)})
+	ownerService := ownerservice.New(&ownerRepository{owners: make(map[string]model.Owner)})
+	carInfoService := carinfoservice.New([]carinfoservice.Provider{carinfoservice.NewProvider("test", provider)}, 4)
+
+	validator, err := openapi.NewValidator([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatalf("can't create validator: %v", err)
+	}
+
+	mux := v1.NewMux(log, v1.Deps{
+		CarService:       carService,
+		OwnerService:     ownerService,
+		CarInfoService:   carInfoService,
+		RequestValidator: validator,
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sdk.New(client.NewHTTPClient(u.Host, "/api/v1", u.Scheme, http.Client{}, client.RetryPolicy{}, nil))
+}
+
+func addKnownCars(t *testing.T, c *sdk.Client) {
+	t.Helper()
+
+	_, err := c.AddCars(context.Background(), sdk.AddCarsInput{RegNumbers: []string{"A111AA111", "B222BB222", "C333CC333"}})
+	if err != nil {
+		t.Fatalf("AddCars: %v", err)
+	}
+}
+
+func regNumbers(cars []sdk.Car) []string {
+	res := make([]string, 0, len(cars))
+	for _, car := range cars {
+		res = append(res, car.RegNumber)
+	}
+	return res
+}
+
+func TestAddCars(t *testing.T) {
+	c := newTestClient(t, &carInfoProvider{cars: knownCars})
+
+	statuses, err := c.AddCars(context.Background(), sdk.AddCarsInput{RegNumbers: []string{"A111AA111", "X000XX000", "A111AA111"}})
+	if err != nil {
+		t.Fatalf("AddCars: %v", err)
+	}
+
+	want := map[string]string{"A111AA111": "valid", "X000XX000": carinfoservice.StatusNotFound}
+	if len(statuses) != len(want) {
+		t.Fatalf("statuses = %v, want %v", statuses, want)
+	}
+	for regNumber, status := range want {
+		if statuses[regNumber] != status {
+			t.Errorf("status of %s = %q, want %q", regNumber, statuses[regNumber], status)
+		}
+	}
+
+	cars, err := c.ListCars(context.Background(), sdk.ListCarsOptions{})
+	if err != nil {
+		t.Fatalf("ListCars: %v", err)
+	}
+	if len(cars) != 1 {
+		t.Fatalf("cars = %v, want only A111AA111", cars)
+	}
+
+	want1 := sdk.Car{RegNumber: "A111AA111", Mark: "Lada", Model: "Vesta", Year: 2020, OwnerName: "Ivan", OwnerSurname: "Ivanov", Source: "test"}
+	if cars[0] != want1 {
+		t.Errorf("car = %+v, want %+v", cars[0], want1)
+	}
+}
+
+func TestAddCarsUpstreamUnavailable(t *testing.T) {
+	c := newTestClient(t, &carInfoProvider{err: client.ErrUpstreamUnavailable})
+
+	_, err := c.AddCars(context.Background(), sdk.AddCarsInput{RegNumbers: []string{"A111AA111"}})
+	if !errors.Is(err, sdk.ErrServiceUnavailable) {
+		t.Fatalf("err = %v, want %v", err, sdk.ErrServiceUnavailable)
+	}
+}
+
+func TestListCars(t *testing.T) {
+	c := newTestClient(t, &carInfoProvider{cars: knownCars})
+	addKnownCars(t, c)
+
+	tests := []struct {
+		name string
+		opts sdk.ListCarsOptions
+		want []string
+	}{
+		{
+			name: "all",
+			want: []string{"A111AA111", "B222BB222", "C333CC333"},
+		},
+		{
+			name: "pagination",
+			opts: sdk.ListCarsOptions{Limit: 1, Offset: 1},
+			want: []string{"B222BB222"},
+		},
+		{
+			name: "field",
+			opts: sdk.ListCarsOptions{Fields: map[string][]string{"mark": {"Lada"}}},
+			want: []string{"A111AA111", "C333CC333"},
+		},
+		{
+			name: "field with operator",
+			opts: sdk.ListCarsOptions{Fields: map[string][]string{"year": {"gt:2010"}}},
+			want: []string{"A111AA111", "B222BB222"},
+		},
+		{
+			name: "field constrained twice",
+			opts: sdk.ListCarsOptions{Fields: map[string][]string{"year": {"gt:2006", "lt:2015"}}},
+			want: []string{"B222BB222"},
+		},
+		{
+			name: "expression",
+			opts: sdk.ListCarsOptions{Filter: "mark eq Kia or (mark eq Lada and year lt 2010)"},
+			want: []string{"B222BB222", "C333CC333"},
+		},
+		{
+			name: "sort",
+			opts: sdk.ListCarsOptions{Sort: "mark,-year"},
+			want: []string{"B222BB222", "A111AA111", "C333CC333"},
+		},
+		{
+			name: "sort before pagination",
+			opts: sdk.ListCarsOptions{Sort: "-year", Limit: 2},
+			want: []string{"A111AA111", "B222BB222"},
+		},
+		{
+			name: "nothing found",
+			opts: sdk.ListCarsOptions{Fields: map[string][]string{"mark": {"Toyota"}}},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cars, err := c.ListCars(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("ListCars: %v", err)
+			}
+
+			if got := regNumbers(cars); !slices.Equal(got, tt.want) {
+				t.Errorf("cars = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCarsInvalidFilter(t *testing.T) {
+	c := newTestClient(t, &carInfoProvider{cars: knownCars})
+
+	_, err := c.ListCars(context.Background(), sdk.ListCarsOptions{Fields: map[string][]string{"year": {"gt:old"}}})
+	if !errors.Is(err, sdk.ErrBadRequest) {
+		t.Fatalf("err = %v, want %v", err, sdk.ErrBadRequest)
+	}
+
+	var apiErr *sdk.Error
+	if !errors.As(err, &apiErr) || !strings.Contains(apiErr.Message, "year") {
+		t.Errorf("err = %v, want message about year", err)
+	}
+}
+
+func TestListCarsInvalidSort(t *testing.T) {
+	c := newTestClient(t, &carInfoProvider{cars: knownCars})
+
+	_, err := c.ListCars(context.Background(), sdk.ListCarsOptions{Sort: "-price"})
+	if !errors.Is(err, sdk.ErrBadRequest) {
+		t.Fatalf("err = %v, want %v", err, sdk.ErrBadRequest)
+	}
+}
+
+func TestUpdateCar(t *testing.T) {
+	c := newTestClient(t, &carInfoProvider{cars: knownCars})
+	addKnownCars(t, c)
+
+	err := c.UpdateCar(context.Background(), "B222BB222", sdk.UpdateCarInput{Model: "Ceed", Year: 2015})
+	if err != nil {
+		t.Fatalf("UpdateCar: %v", err)
+	}
+
+	cars, err := c.ListCars(context.Background(), sdk.ListCarsOptions{Fields: map[string][]string{"regNumber": {"B222BB222"}}})
+	if err != nil {
+		t.Fatalf("ListCars: %v", err)
+	}
+
+	want := sdk.Car{RegNumber: "B222BB222", Mark: "Kia", Model: "Ceed", Year: 2015, OwnerName: "Petr", OwnerSurname: "Petrov", Source: "test"}
+	if len(cars) != 1 || cars[0] != want {
+		t.Errorf("cars = %+v, want %+v", cars, want)
+	}
+
+	err = c.UpdateCar(context.Background(), "X000XX000", sdk.UpdateCarInput{Model: "Ceed"})
+	if !errors.Is(err, sdk.ErrBadRequest) {
+		t.Errorf("err = %v, want %v", err, sdk.ErrBadRequest)
+	}
+}
+
+func TestDeleteCar(t *testing.T) {
+	c := newTestClient(t, &carInfoProvider{cars: knownCars})
+	addKnownCars(t, c)
+
+	if err := c.DeleteCar(context.Background(), "A111AA111"); err != nil {
+		t.Fatalf("DeleteCar: %v", err)
+	}
+
+	cars, err := c.ListCars(context.Background(), sdk.ListCarsOptions{})
+	if err != nil {
+		t.Fatalf("ListCars: %v", err)
+	}
+	if got, want := regNumbers(cars), []string{"B222BB222", "C333CC333"}; !slices.Equal(got, want) {
+		t.Errorf("cars = %v, want %v", got, want)
+	}
+
+	err = c.DeleteCar(context.Background(), "A111AA111")
+	if !errors.Is(err, sdk.ErrBadRequest) {
+		t.Errorf("err = %v, want %v", err, sdk.ErrBadRequest)
+	}
+}
+
+func TestRegNumberIsEscaped(t *testing.T) {
+	c := newTestClient(t, &carInfoProvider{cars: knownCars})
+	addKnownCars(t, c)
+
+	for _, regNumber := range []string{"X/../B222BB222", "../cars/B222BB222", "..", "B222BB222?x=1"} {
+		err := c.DeleteCar(context.Background(), regNumber)
+		if !errors.Is(err, sdk.ErrBadRequest) {
+			t.Errorf("DeleteCar(%q) err = %v, want %v", regNumber, err, sdk.ErrBadRequest)
+		}
+	}
+
+	cars, err := c.ListCars(context.Background(), sdk.ListCarsOptions{})
+	if err != nil {
+		t.Fatalf("ListCars: %v", err)
+	}
+	if got, want := regNumbers(cars), []string{"A111AA111", "B222BB222", "C333CC333"}; !slices.Equal(got, want) {
+		t.Errorf("cars = %v, want %v", got, want)
+	}
+}